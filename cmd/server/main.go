@@ -3,33 +3,211 @@ package main
 import (
 	"Zephyr/internal/api"
 	"Zephyr/internal/config"
-	"Zephyr/internal/providers/qweather"
-	"log"
+	"Zephyr/internal/logging"
+	"Zephyr/internal/metrics"
+	"Zephyr/internal/middleware"
+	"Zephyr/internal/notify"
+	"Zephyr/internal/providers/qweather/auth"
+	"Zephyr/internal/subscriptions"
+	"Zephyr/internal/tracing"
+	"Zephyr/pkg/proxyproto"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 func main() {
 	// Load configuration from .env file
-	config.LoadConfig()
+	envErr := config.LoadConfig()
+
+	// Structured logging with credential redaction. Nothing can be logged
+	// through the shared logger before this succeeds.
+	if err := logging.Init(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logging: %v\n", err)
+		os.Exit(1)
+	}
+	logger := logging.L()
+	if envErr != nil {
+		logger.Warn(".env file not found, using environment variables or defaults", zap.Error(envErr))
+	}
+
+	// Spans are exported to OTEL_EXPORTER_OTLP_ENDPOINT when configured
+	shutdownTracing, err := tracing.Init(config.Ctx)
+	if err != nil {
+		logger.Fatal("Failed to initialize tracing", zap.Error(err))
+	}
+	go flushOnExit(shutdownTracing)
 
 	// Initialize Redis
-	config.InitRedis()
+	if err := config.InitRedis(); err != nil {
+		logger.Error("Failed to connect to Redis", zap.Error(err))
+	} else {
+		logger.Info("Successfully connected to Redis")
+	}
+	config.RedisClient.AddHook(metrics.RedisHook{})
 
-	r := gin.Default()
+	// Parse the QWeather signing keys once, and again on SIGHUP so keys can
+	// be rotated without a restart
+	if err := auth.Reload(config.QweatherConfig); err != nil {
+		logger.Warn("QWeather keys not loaded", zap.Error(err))
+	}
+	go reloadOnSighup()
+
+	// Webhooks may only reach public addresses unless allow-listed
+	if err := subscriptions.SetAllowedNetworks(config.WebhookAllowedNetworks); err != nil {
+		logger.Fatal("Invalid WEBHOOK_ALLOWED_NETWORKS", zap.Error(err))
+	}
+
+	// Watch subscribed locations and deliver alert changes to webhooks
+	go subscriptions.NewPoller(api.AlertSources).Run(config.Ctx)
+
+	// Push alerts and rain notifications to registered devices
+	go notify.NewPoller(api.AlertSources, notify.NewDispatcherFromConfig()).Run(config.Ctx)
+
+	// Only trusted proxies may report the client address
+	if err := middleware.SetTrustedProxies(config.TrustedProxies); err != nil {
+		logger.Fatal("Invalid TRUSTED_PROXIES", zap.Error(err))
+	}
+	if err := middleware.SetProxyHeader(config.TrustedProxyHeader); err != nil {
+		logger.Fatal("Invalid TRUSTED_PROXY_HEADER", zap.Error(err))
+	}
+
+	if err := middleware.SetIPLists(config.IPAllowList, config.IPDenyList); err != nil {
+		logger.Fatal("Invalid IP_ALLOW_LIST or IP_DENY_LIST", zap.Error(err))
+	}
+
+	if err := middleware.SetSigningKeys(config.SigningKeys); err != nil {
+		logger.Fatal("Invalid SIGNING_KEYS", zap.Error(err))
+	}
+
+	r := gin.New()
+	// Every request gets a span and an ID and is logged through the shared
+	// logger. Metrics wraps Recovery so recovered panics are counted as 500s.
+	r.Use(middleware.Tracing(), middleware.RequestID(), middleware.Metrics(), gin.Recovery())
+	if err := r.SetTrustedProxies(config.TrustedProxies); err != nil {
+		logger.Fatal("Invalid TRUSTED_PROXIES", zap.Error(err))
+	}
+	if err := middleware.SetProxyHeader(config.TrustedProxyHeader); err != nil {
+		logger.Fatal("Invalid TRUSTED_PROXY_HEADER", zap.Error(err))
+	}
+
+	// Denied and banned clients are rejected before any route, then every
+	// request counts towards anomaly bans before any limiter can reject it
+	r.Use(middleware.IPFilter())
+	r.Use(middleware.BanAnomalies(middleware.AnomalyConfig{
+		Threshold: config.AnomalyThreshold,
+		Window:    config.AnomalyWindow,
+	}))
+
+	// Signed requests from first-party apps are verified for every route
+	r.Use(middleware.VerifySignature())
+
+	// Prometheus scrape endpoint, protected by METRICS_TOKEN when set
+	r.GET("/metrics", middleware.MetricsHandler())
+
+	// The health check has its own protection and stays open to monitors
+	r.GET("/api/v1/healthcheck", middleware.RateLimit(middleware.RateLimitConfig{
+		Name:    "health_check",
+		Limit:   api.RateLimitPerMinute,
+		Window:  api.RateLimitWindowSeconds * time.Second,
+		KeyFunc: middleware.ByIP,
+	}), api.HealthCheck)
+
+	// Admin routes authenticate with ADMIN_TOKEN instead of an API key and
+	// are limited per IP to slow down token guessing
+	admin := r.Group("/api/v1/admin", middleware.RateLimit(middleware.RateLimitConfig{
+		Name:    "admin",
+		Limit:   30,
+		Window:  time.Minute,
+		KeyFunc: middleware.ByIP,
+	}), middleware.RequireAdmin())
+	admin.POST("/keys", api.CreateAPIKey)
+	admin.GET("/keys/:id", api.GetAPIKey)
+	admin.DELETE("/keys/:id", api.RevokeAPIKey)
+	admin.GET("/bans", api.ListBans)
+	admin.DELETE("/bans/:ip", api.LiftBan)
 
 	// API routes
-	r.GET("/api/v1/city/search", api.SearchCities)
-	r.GET("/api/v1/weather/alert", qweather.WeatherWarningFromQweather)
-	r.GET("/api/v1/weather/forecast", api.Forecast)
-	r.GET("/api/v1/healthcheck", api.HealthCheck)
+	v1 := r.Group("/api/v1", middleware.RequireAPIKey(), middleware.RateLimit(middleware.RateLimitConfig{
+		Name:    "api",
+		Limit:   config.RateLimitRequests,
+		Window:  config.RateLimitWindow,
+		KeyFunc: middleware.ByAPIKey,
+	}))
+	v1.GET("/city/search", api.SearchCities)
+	v1.POST("/locations", api.CreateLocation)
+	v1.GET("/locations/:id", api.GetLocation)
+	v1.GET("/weather/alert", api.WeatherAlert)
+	v1.GET("/weather/forecast", api.Forecast)
+	v1.POST("/weather/forecast/batch", api.BatchForecast)
+	v1.GET("/weather/indices", api.LifestyleIndices)
+	v1.GET("/weather/marine", api.Marine)
+	v1.GET("/air", api.AirQuality)
+	v1.GET("/ocean/tide", api.Tide)
+	v1.GET("/stream", api.Stream)
+	v1.GET("/ws", api.WebSocket)
+	v1.POST("/subscriptions", api.CreateSubscription)
+	v1.GET("/subscriptions/:id", api.GetSubscription)
+	v1.DELETE("/subscriptions/:id", api.DeleteSubscription)
+	v1.POST("/devices", api.RegisterDevice)
+	v1.DELETE("/devices/:platform/:token", api.UnregisterDevice)
+
+	listener, err := net.Listen("tcp", config.ServerPort)
+	if err != nil {
+		logger.Fatal("Failed to listen", zap.String("addr", config.ServerPort), zap.Error(err))
+	}
+	if config.ProxyProtocol {
+		listener = proxyproto.NewListener(listener, middleware.IsTrustedProxy)
+	}
+	server := &http.Server{Handler: r.Handler()}
 
 	// Start server with configuration
 	if config.EnableTLS {
-		log.Printf("Starting HTTPS server on %s", config.ServerPort)
-		r.RunTLS(config.ServerPort, config.CertFile, config.KeyFile)
+		logger.Info("Starting HTTPS server", zap.String("addr", config.ServerPort))
+		err = server.ServeTLS(listener, config.CertFile, config.KeyFile)
 	} else {
-		log.Printf("Starting HTTP server on %s", config.ServerPort)
-		r.Run(config.ServerPort)
+		logger.Info("Starting HTTP server", zap.String("addr", config.ServerPort))
+		err = server.Serve(listener)
+	}
+	logger.Fatal("Server stopped", zap.Error(err))
+}
+
+// reloadOnSighup reloads the .env file and QWeather keys on every SIGHUP.
+// A broken configuration is logged and the previous keys stay in use.
+func reloadOnSighup() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	for range signals {
+		if err := config.ReloadEnv(); err != nil {
+			logging.L().Warn("Failed to reload .env file", zap.Error(err))
+		}
+		if err := auth.Reload(config.ReadQweatherConfig()); err != nil {
+			logging.L().Error("Failed to reload QWeather keys", zap.Error(err))
+			continue
+		}
+		logging.L().Info("Reloaded QWeather keys")
+	}
+}
+
+// flushOnExit exports buffered spans before the process stops on SIGINT or
+// SIGTERM
+func flushOnExit(shutdown func(context.Context) error) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	<-signals
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdown(ctx); err != nil {
+		logging.L().Error("Failed to flush traces", zap.Error(err))
 	}
+	os.Exit(0)
 }
@@ -0,0 +1,80 @@
+package alerts
+
+import (
+	"Zephyr/internal/logging"
+	"Zephyr/internal/models"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// Source is a provider of weather alerts for a point
+type Source interface {
+	Name() string
+	Fetch(ctx context.Context, latitude, longitude float64, language string) ([]models.Alert, error)
+}
+
+// Collect queries every source concurrently and merges the results.
+// Sources earlier in the list win when the same alert is reported twice.
+// The names of sources that failed are returned so callers can tell a
+// missing alert from an unavailable source; an error is only returned when
+// every source failed.
+func Collect(ctx context.Context, sources []Source, latitude, longitude float64, language string) ([]models.Alert, []string, error) {
+	results := make([][]models.Alert, len(sources))
+	errs := make([]error, len(sources))
+
+	var wg sync.WaitGroup
+	wg.Add(len(sources))
+	for i, source := range sources {
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = source.Fetch(ctx, latitude, longitude, language)
+			if errs[i] != nil {
+				logging.FromContext(ctx).Warn("Failed to fetch alerts", zap.String("provider", source.Name()), zap.Error(errs[i]))
+			}
+		}()
+	}
+	wg.Wait()
+
+	var failed []string
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, sources[i].Name())
+		}
+	}
+	if len(sources) > 0 && len(failed) == len(sources) {
+		return nil, failed, errors.Join(errs...)
+	}
+
+	var merged []models.Alert
+	for _, alerts := range results {
+		merged = append(merged, alerts...)
+	}
+	return Deduplicate(merged), failed, nil
+}
+
+// Deduplicate drops alerts whose ID or headline was already seen.
+// Agencies often publish the same warning through several channels
+// with different identifiers but an identical headline.
+func Deduplicate(alerts []models.Alert) []models.Alert {
+	seen := make(map[string]bool)
+	result := make([]models.Alert, 0, len(alerts))
+	for _, alert := range alerts {
+		idKey := "id:" + alert.ID
+		headlineKey := "headline:" + strings.ToLower(strings.TrimSpace(alert.Headline))
+		if (alert.ID != "" && seen[idKey]) || (alert.Headline != "" && seen[headlineKey]) {
+			continue
+		}
+		if alert.ID != "" {
+			seen[idKey] = true
+		}
+		if alert.Headline != "" {
+			seen[headlineKey] = true
+		}
+		result = append(result, alert)
+	}
+	return result
+}
@@ -0,0 +1,106 @@
+package alerts
+
+import (
+	"Zephyr/internal/models"
+	"strings"
+	"time"
+)
+
+// ParseSeverity maps a CAP or provider severity string onto the CAP enum.
+// QWeather also uses "Major" and "Standard" outside the CAP vocabulary.
+func ParseSeverity(value string) models.AlertSeverity {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "extreme":
+		return models.SeverityExtreme
+	case "severe", "major":
+		return models.SeveritySevere
+	case "moderate":
+		return models.SeverityModerate
+	case "minor", "standard":
+		return models.SeverityMinor
+	}
+	return models.SeverityUnknown
+}
+
+func ParseUrgency(value string) models.AlertUrgency {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "immediate":
+		return models.UrgencyImmediate
+	case "expected":
+		return models.UrgencyExpected
+	case "future":
+		return models.UrgencyFuture
+	case "past":
+		return models.UrgencyPast
+	}
+	return models.UrgencyUnknown
+}
+
+func ParseCertainty(value string) models.AlertCertainty {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "observed":
+		return models.CertaintyObserved
+	case "likely":
+		return models.CertaintyLikely
+	case "possible":
+		return models.CertaintyPossible
+	case "unlikely":
+		return models.CertaintyUnlikely
+	}
+	return models.CertaintyUnknown
+}
+
+// colorNames matches English and Chinese color names found in provider fields
+var colorNames = []struct {
+	color models.AlertColor
+	names []string
+}{
+	{models.ColorWhite, []string{"white", "白"}},
+	{models.ColorGreen, []string{"green", "绿"}},
+	{models.ColorBlue, []string{"blue", "蓝"}},
+	{models.ColorYellow, []string{"yellow", "黄"}},
+	{models.ColorOrange, []string{"orange", "橙"}},
+	{models.ColorRed, []string{"red", "红"}},
+	{models.ColorBlack, []string{"black", "黑"}},
+}
+
+// ParseColor finds a warning color in a provider string such as "Yellow"
+// or "黄色", returning ColorUnknown when none is present
+func ParseColor(value string) models.AlertColor {
+	value = strings.ToLower(value)
+	for _, entry := range colorNames {
+		for _, name := range entry.names {
+			if strings.Contains(value, name) {
+				return entry.color
+			}
+		}
+	}
+	return models.ColorUnknown
+}
+
+// ColorForSeverity derives a color for sources that only publish severity
+func ColorForSeverity(severity models.AlertSeverity) models.AlertColor {
+	switch severity {
+	case models.SeverityExtreme:
+		return models.ColorRed
+	case models.SeveritySevere:
+		return models.ColorOrange
+	case models.SeverityModerate:
+		return models.ColorYellow
+	case models.SeverityMinor:
+		return models.ColorBlue
+	}
+	return models.ColorUnknown
+}
+
+// ParseTime parses RFC 3339 timestamps, including the minute precision
+// form QWeather uses ("2006-01-02T15:04+08:00"). Empty or invalid values
+// return nil.
+func ParseTime(value string) *time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04Z07:00"} {
+		if t, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
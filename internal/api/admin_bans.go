@@ -0,0 +1,33 @@
+package api
+
+import (
+	"Zephyr/internal/bans"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListBans returns all active bans
+func ListBans(c *gin.Context) {
+	list, err := bans.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"bans": list})
+}
+
+// LiftBan unbans an IP and resets its offence count
+func LiftBan(c *gin.Context) {
+	err := bans.Lift(c.Param("ip"))
+	if errors.Is(err, bans.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"Zephyr/internal/apikeys"
+	"Zephyr/internal/models"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type apiKeyRequest struct {
+	Name         string `json:"name"`
+	DailyQuota   int64  `json:"daily_quota"`
+	MonthlyQuota int64  `json:"monthly_quota"`
+}
+
+// apiKeyResponse never includes the stored hash
+type apiKeyResponse struct {
+	models.APIKey
+	Key   string              `json:"key,omitempty"`
+	Usage *models.APIKeyUsage `json:"usage,omitempty"`
+}
+
+// CreateAPIKey issues a key. The plaintext key is only returned here.
+func CreateAPIKey(c *gin.Context) {
+	var req apiKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	if req.Name == "" || req.DailyQuota < 0 || req.MonthlyQuota < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required and quotas must not be negative"})
+		return
+	}
+
+	apiKey, key, err := apikeys.Create(req.Name, req.DailyQuota, req.MonthlyQuota)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	apiKey.Hash = ""
+	c.JSON(http.StatusCreated, apiKeyResponse{APIKey: apiKey, Key: key})
+}
+
+// GetAPIKey returns a key's settings and its usage in the current day and month
+func GetAPIKey(c *gin.Context) {
+	apiKey, err := apikeys.Get(c.Param("id"))
+	if errors.Is(err, apikeys.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	usage, err := apikeys.Usage(apiKey.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	apiKey.Hash = ""
+	c.JSON(http.StatusOK, apiKeyResponse{APIKey: apiKey, Usage: &usage})
+}
+
+func RevokeAPIKey(c *gin.Context) {
+	apiKey, err := apikeys.Revoke(c.Param("id"))
+	if errors.Is(err, apikeys.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	apiKey.Hash = ""
+	c.JSON(http.StatusOK, apiKeyResponse{APIKey: apiKey})
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"Zephyr/internal/models"
+	"Zephyr/internal/providers/openmeteo"
+	"Zephyr/internal/providers/qweather"
+	"Zephyr/pkg/aqi"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultAirQualityStandards are the indices computed when aqi_standard is not given
+var defaultAirQualityStandards = []aqi.Standard{aqi.USEPA, aqi.EUCAQI, aqi.ChinaHJ633}
+
+func AirQuality(c *gin.Context) {
+	latitude, longitude, ok := queryCoordinates(c)
+	if !ok {
+		return
+	}
+	language := c.Query("accept-language")
+	source := c.Query("source")
+
+	standards, err := parseAQIStandards(c.Query("aqi_standard"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(standards) == 0 {
+		standards = defaultAirQualityStandards
+	}
+
+	var airQualityResult models.AirQualityResult
+	switch source {
+	case "om":
+		airQualityResult, err = openmeteo.GetAirQualityDetails(c.Request.Context(), latitude, longitude, language)
+	case "qweather":
+		airQualityResult, err = qweather.GetAirQualityDetails(c.Request.Context(), latitude, longitude, language)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported source"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	applyAirQualityIndices(&airQualityResult, standards)
+	c.JSON(http.StatusOK, airQualityResult)
+}
+
+// parseAQIStandards parses a comma separated aqi_standard query value
+func parseAQIStandards(value string) ([]aqi.Standard, error) {
+	var standards []aqi.Standard
+	for _, name := range strings.Split(value, ",") {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		standard, err := aqi.ParseStandard(name)
+		if err != nil {
+			return nil, err
+		}
+		standards = append(standards, standard)
+	}
+	return standards, nil
+}
+
+// applyAirQualityIndices computes the requested indices from the pollutant
+// concentrations so values are comparable regardless of the source
+func applyAirQualityIndices(result *models.AirQualityResult, standards []aqi.Standard) {
+	result.Current.Indices = computeIndices(result.Current.Pollutants, standards)
+	for i := range result.Hourly {
+		result.Hourly[i].Indices = computeIndices(result.Hourly[i].Pollutants, standards)
+	}
+	for i := range result.Daily {
+		result.Daily[i].Indices = computeIndices(result.Daily[i].Pollutants, standards)
+	}
+}
+
+func computeIndices(pollutants models.Pollutants, standards []aqi.Standard) map[string]models.AQIIndex {
+	concentrations := aqi.Concentrations{
+		PM25: pollutants.Pm25,
+		PM10: pollutants.Pm10,
+		O3:   pollutants.Ozone,
+		NO2:  pollutants.NitrogenDioxide,
+		SO2:  pollutants.SulfurDioxide,
+		CO:   pollutants.CarbonMonoxide,
+	}
+
+	indices := make(map[string]models.AQIIndex, len(standards))
+	for _, standard := range standards {
+		result, err := aqi.Calculate(standard, concentrations)
+		if err != nil {
+			continue
+		}
+		indices[string(standard)] = models.AQIIndex{
+			Value:            result.Value,
+			Category:         result.Category,
+			HealthAdvice:     result.HealthAdvice,
+			PrimaryPollutant: result.PrimaryPollutant,
+		}
+	}
+	return indices
+}
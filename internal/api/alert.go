@@ -0,0 +1,142 @@
+package api
+
+import (
+	"Zephyr/internal/alerts"
+	"Zephyr/internal/config"
+	"Zephyr/internal/middleware"
+	"Zephyr/internal/models"
+	"Zephyr/internal/providers/capfeed"
+	"Zephyr/internal/providers/qweather"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AlertSources returns the sources to query. QWeather is skipped for
+// Open-Meteo users so they do not consume the QWeather quota.
+func AlertSources(source string) []alerts.Source {
+	var sources []alerts.Source
+	if source != "om" {
+		sources = append(sources, qweather.AlertSource{})
+	}
+	for _, feed := range config.CapFeedUrls {
+		sources = append(sources, capfeed.NewFeedSource(feed))
+	}
+	return sources
+}
+
+// parseAlertLocation reads "location=lon,lat" or latitude/longitude parameters
+func parseAlertLocation(c *gin.Context) (float64, float64, bool) {
+	latitude, longitude := c.Query("latitude"), c.Query("longitude")
+	if location := c.Query("location"); location != "" {
+		parts := strings.Split(location, ",")
+		if len(parts) != 2 {
+			return 0, 0, false
+		}
+		longitude, latitude = strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+	}
+
+	lat, err1 := strconv.ParseFloat(latitude, 64)
+	lon, err2 := strconv.ParseFloat(longitude, 64)
+	if err1 != nil || err2 != nil {
+		return 0, 0, false
+	}
+	return lat, lon, true
+}
+
+func WeatherAlert(c *gin.Context) {
+	lang := c.DefaultQuery("lang", "zh")
+	source := c.Query("source")
+	id := c.Query("id")
+
+	var latitude, longitude float64
+	if locationID := c.Query("location_id"); locationID != "" {
+		location, ok := resolveLocation(c, locationID)
+		if !ok {
+			return
+		}
+		latitude, longitude = location.Latitude, location.Longitude
+	} else {
+		var ok bool
+		if latitude, longitude, ok = parseAlertLocation(c); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "location parameter is required"})
+			return
+		}
+	}
+
+	alertList := []models.Alert{}
+	if sources := AlertSources(source); len(sources) > 0 {
+		var err error
+		if alertList, _, err = alerts.Collect(c.Request.Context(), sources, latitude, longitude, lang); err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	// An id selects a single alert, which is how CAP documents are addressed
+	if id != "" {
+		var selected []models.Alert
+		for _, alert := range alertList {
+			if alert.ID == id {
+				selected = append(selected, alert)
+			}
+		}
+		if len(selected) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "alert not found"})
+			return
+		}
+		alertList = selected
+	}
+
+	renderAlerts(c, alertList)
+}
+
+// publicFeedURL is the request URL without credentials. Feed readers store
+// and share the feed id and self link, so an api_key parameter must not
+// end up there.
+func publicFeedURL(r *http.Request) string {
+	query := r.URL.Query()
+	query.Del(middleware.APIKeyQueryParam)
+
+	feedURL := url.URL{Scheme: "http", Host: r.Host, Path: r.URL.Path, RawQuery: query.Encode()}
+	if r.TLS != nil {
+		feedURL.Scheme = "https"
+	}
+	return feedURL.String()
+}
+
+// renderAlerts writes alerts as JSON, CAP 1.2, ATOM or RSS depending on the Accept header
+func renderAlerts(c *gin.Context, alertList []models.Alert) {
+	format := c.NegotiateFormat(gin.MIMEJSON, capfeed.MIMECAP, capfeed.MIMEAtom, capfeed.MIMERSS)
+
+	feedURL := publicFeedURL(c.Request)
+
+	var body []byte
+	var err error
+	switch format {
+	case capfeed.MIMECAP:
+		// A CAP document holds exactly one alert
+		if len(alertList) != 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "CAP output requires exactly one alert, select it with the id parameter"})
+			return
+		}
+		body, err = capfeed.RenderCAP(alertList[0], time.Now())
+	case capfeed.MIMEAtom:
+		body, err = capfeed.RenderAtom(alertList, feedURL, time.Now())
+	case capfeed.MIMERSS:
+		body, err = capfeed.RenderRSS(alertList, feedURL, time.Now())
+	default:
+		c.JSON(http.StatusOK, models.AlertResult{Alerts: alertList})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Data(http.StatusOK, format+"; charset=utf-8", body)
+}
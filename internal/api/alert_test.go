@@ -0,0 +1,69 @@
+package api
+
+import (
+	"Zephyr/internal/models"
+	"Zephyr/internal/providers/capfeed"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPublicFeedURL(t *testing.T) {
+	cases := []struct {
+		name   string
+		target string
+		tls    bool
+		want   string
+	}{
+		{"no query", "/api/v1/weather/alert", false, "http://zeus.example/api/v1/weather/alert"},
+		{"api key removed", "/api/v1/weather/alert?latitude=52.52&api_key=secret&longitude=13.41", false,
+			"http://zeus.example/api/v1/weather/alert?latitude=52.52&longitude=13.41"},
+		{"only api key", "/api/v1/weather/alert?api_key=secret", true, "https://zeus.example/api/v1/weather/alert"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "http://zeus.example"+c.target, nil)
+			if c.tls {
+				r.TLS = &tls.ConnectionState{}
+			}
+			if got := publicFeedURL(r); got != c.want {
+				t.Errorf("publicFeedURL = %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+// TestRenderedFeedsOmitAPIKey fails if a key passed as a query parameter
+// reaches the feed id or links that readers store
+func TestRenderedFeedsOmitAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const key = "zk_0123456789abcdef"
+	alertList := []models.Alert{{ID: "a", Source: "qweather", Headline: "Fog", Sent: time.Now()}}
+
+	for _, format := range []string{capfeed.MIMEAtom, capfeed.MIMERSS} {
+		t.Run(format, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet,
+				"http://zeus.example/api/v1/weather/alert?latitude=52.52&longitude=13.41&api_key="+key, nil)
+			c.Request.Header.Set("Accept", format)
+
+			renderAlerts(c, alertList)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			body := w.Body.String()
+			if strings.Contains(body, key) || strings.Contains(body, "api_key") {
+				t.Errorf("API key leaked into the feed:\n%s", body)
+			}
+			if !strings.Contains(body, "latitude=52.52") {
+				t.Errorf("feed URL lost its query:\n%s", body)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"Zephyr/internal/models"
+	"Zephyr/internal/notify"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type deviceRequest struct {
+	Token     string   `json:"token"`
+	Platform  string   `json:"platform"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	Language  string   `json:"language"`
+	Source    string   `json:"source"`
+}
+
+// RegisterDevice registers or updates a device for push notifications.
+// Apps call it again whenever the token, location or language changes.
+func RegisterDevice(c *gin.Context) {
+	var req deviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if req.Token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return
+	}
+	if req.Platform != models.PlatformFCM && req.Platform != models.PlatformAPNs {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "platform must be fcm or apns"})
+		return
+	}
+	if !notify.ValidToken(req.Platform, req.Token) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid token for platform " + req.Platform})
+		return
+	}
+	if req.Latitude == nil || req.Longitude == nil ||
+		*req.Latitude < -90 || *req.Latitude > 90 || *req.Longitude < -180 || *req.Longitude > 180 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "valid latitude and longitude are required"})
+		return
+	}
+
+	if req.Language == "" {
+		req.Language = "zh"
+	}
+
+	device, err := notify.Register(models.Device{
+		Token:     req.Token,
+		Platform:  req.Platform,
+		Latitude:  *req.Latitude,
+		Longitude: *req.Longitude,
+		Language:  req.Language,
+		Source:    req.Source,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, device)
+}
+
+func UnregisterDevice(c *gin.Context) {
+	found, err := notify.Unregister(c.Param("platform"), c.Param("token"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "device not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -1,31 +1,64 @@
 package api
 
 import (
+	"Zephyr/internal/models"
 	"Zephyr/internal/providers/openmeteo"
 	"Zephyr/internal/providers/qweather"
+	"Zephyr/pkg/aqi"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
 func Forecast(c *gin.Context) {
-	latitude := c.Query("latitude")
-	longitude := c.Query("longitude")
+	latitude, longitude, ok := queryCoordinates(c)
+	if !ok {
+		return
+	}
 	unit := c.Query("unit")
 	language := c.Query("accept-language")
 	source := c.Query("source")
 
+	var standard aqi.Standard
+	if value := c.Query("aqi_standard"); value != "" {
+		var err error
+		if standard, err = aqi.ParseStandard(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var weatherResult models.WeatherResult
 	switch source {
 	case "om":
-		weatherResult := openmeteo.GetAllForecastDetails(latitude, longitude, language, unit)
-		c.JSON(http.StatusOK, weatherResult)
-		return
+		weatherResult = openmeteo.GetAllForecastDetails(c.Request.Context(), latitude, longitude, language, unit)
 	case "qweather":
-		weatherResult := qweather.GetAllForecastDetails(latitude, longitude, language, unit)
-		c.JSON(http.StatusOK, weatherResult)
-		return
+		weatherResult = qweather.GetAllForecastDetails(c.Request.Context(), latitude, longitude, language, unit)
 	default:
 		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported source"})
 		return
 	}
+
+	// Replace the provider AQI so every source reports the same scale
+	if standard != "" {
+		applyCurrentAQI(&weatherResult.CWR, standard)
+	}
+	c.JSON(http.StatusOK, weatherResult)
+}
+
+// applyCurrentAQI recomputes the current AQI from concentrations in the given standard
+func applyCurrentAQI(current *models.CurrentWeatherResult, standard aqi.Standard) {
+	result, err := aqi.Calculate(standard, aqi.Concentrations{
+		PM25: current.Pm25,
+		PM10: current.Pm10,
+		O3:   current.Ozone,
+		NO2:  current.NitrogenDioxide,
+		SO2:  current.SulfurDioxide,
+	})
+	if err != nil {
+		return
+	}
+	current.AQI = float64(result.Value)
+	current.AQIStandard = string(result.Standard)
+	current.AQICategory = result.Category
 }
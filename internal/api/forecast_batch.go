@@ -0,0 +1,127 @@
+package api
+
+import (
+	"Zephyr/internal/config"
+	"Zephyr/internal/models"
+	"Zephyr/internal/providers/openmeteo"
+	"Zephyr/internal/providers/qweather"
+	"Zephyr/pkg/aqi"
+	"Zephyr/pkg/utils"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Open-Meteo locations per multi-coordinate request, keeping URLs short
+const omBatchChunkSize = 25
+
+type batchForecastRequest struct {
+	Locations []struct {
+		ID        string   `json:"id"`
+		Latitude  *float64 `json:"latitude"`
+		Longitude *float64 `json:"longitude"`
+	} `json:"locations"`
+	Source      string `json:"source"`
+	Unit        string `json:"unit"`
+	Language    string `json:"language"`
+	AQIStandard string `json:"aqi_standard"`
+}
+
+// BatchForecast returns forecasts for several locations in one call. A
+// failing location carries an error without failing the whole request.
+func BatchForecast(c *gin.Context) {
+	var req batchForecastRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if len(req.Locations) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "locations are required"})
+		return
+	}
+	if len(req.Locations) > config.BatchMaxLocations {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d locations per request", config.BatchMaxLocations)})
+		return
+	}
+	if req.Source != "om" && req.Source != "qweather" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported source"})
+		return
+	}
+
+	var standard aqi.Standard
+	if req.AQIStandard != "" {
+		var err error
+		if standard, err = aqi.ParseStandard(req.AQIStandard); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	// Invalid locations get an error; the rest are fetched
+	results := make([]models.BatchForecastItem, len(req.Locations))
+	var valid []int
+	for i, location := range req.Locations {
+		results[i].ID = location.ID
+		if location.Latitude == nil || location.Longitude == nil ||
+			*location.Latitude < -90 || *location.Latitude > 90 || *location.Longitude < -180 || *location.Longitude > 180 {
+			results[i].Error = "valid latitude and longitude are required"
+			continue
+		}
+		results[i].Latitude = *location.Latitude
+		results[i].Longitude = *location.Longitude
+		valid = append(valid, i)
+	}
+
+	var jobs []func()
+	switch req.Source {
+	case "om":
+		// Open-Meteo takes many coordinates per request, so each job is a chunk
+		for start := 0; start < len(valid); start += omBatchChunkSize {
+			chunk := valid[start:min(start+omBatchChunkSize, len(valid))]
+			jobs = append(jobs, func() {
+				coordinates := make([]models.Coordinates, len(chunk))
+				for j, i := range chunk {
+					coordinates[j] = models.Coordinates{Latitude: results[i].Latitude, Longitude: results[i].Longitude}
+				}
+				forecasts, errs := openmeteo.GetBatchForecastDetails(c.Request.Context(), coordinates, req.Language, req.Unit)
+				for j, i := range chunk {
+					setBatchResult(&results[i], forecasts[j], errs[j])
+				}
+			})
+		}
+	case "qweather":
+		for _, i := range valid {
+			jobs = append(jobs, func() {
+				latitude := fmt.Sprintf("%f", results[i].Latitude)
+				longitude := fmt.Sprintf("%f", results[i].Longitude)
+				forecast := qweather.GetAllForecastDetails(c.Request.Context(), latitude, longitude, req.Language, req.Unit)
+				// The provider returns an empty result when any upstream call fails
+				var err error
+				if len(forecast.HWR) == 0 && len(forecast.DWR) == 0 {
+					err = fmt.Errorf("failed to fetch forecast")
+				}
+				setBatchResult(&results[i], forecast, err)
+			})
+		}
+	}
+	utils.RunBatch(jobs, config.BatchWorkers)
+
+	if standard != "" {
+		for i := range results {
+			if results[i].Forecast != nil {
+				applyCurrentAQI(&results[i].Forecast.CWR, standard)
+			}
+		}
+	}
+	c.JSON(http.StatusOK, models.BatchForecastResult{Results: results})
+}
+
+func setBatchResult(item *models.BatchForecastItem, forecast models.WeatherResult, err error) {
+	if err != nil {
+		item.Error = err.Error()
+		return
+	}
+	item.Forecast = &forecast
+}
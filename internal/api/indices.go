@@ -0,0 +1,44 @@
+package api
+
+import (
+	"Zephyr/internal/logging"
+	"Zephyr/internal/models"
+	"Zephyr/internal/providers/openmeteo"
+	"Zephyr/internal/providers/qweather"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func LifestyleIndices(c *gin.Context) {
+	latitude := c.Query("latitude")
+	longitude := c.Query("longitude")
+	language := c.Query("accept-language")
+	source := c.Query("source")
+
+	var indices []models.LifestyleIndex
+	var err error
+	switch source {
+	case "om":
+		indices, err = openmeteo.GetLifestyleIndices(c.Request.Context(), latitude, longitude, language)
+	case "qweather":
+		indices, err = qweather.GetLifestyleIndices(c.Request.Context(), latitude, longitude, language)
+		// QWeather indices do not cover every region, fall back to computed ones
+		if err != nil || len(indices) == 0 {
+			if err != nil {
+				logging.FromContext(c.Request.Context()).Info("QWeather indices unavailable, using computed indices", zap.String("provider", "qweather"), zap.Error(err))
+			}
+			indices, err = openmeteo.GetLifestyleIndices(c.Request.Context(), latitude, longitude, language)
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported source"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, indices)
+}
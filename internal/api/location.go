@@ -0,0 +1,89 @@
+package api
+
+import (
+	"Zephyr/internal/locations"
+	"Zephyr/internal/models"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type locationRequest struct {
+	Name      string   `json:"name"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	State     string   `json:"state"`
+	Country   string   `json:"country"`
+}
+
+// CreateLocation issues a Zeus location ID for a place, e.g. a favorite
+// that did not come from a provider search
+func CreateLocation(c *gin.Context) {
+	var req locationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	if req.Latitude == nil || req.Longitude == nil ||
+		*req.Latitude < -90 || *req.Latitude > 90 || *req.Longitude < -180 || *req.Longitude > 180 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "valid latitude and longitude are required"})
+		return
+	}
+
+	location, err := locations.Issue(models.Location{
+		Name:      req.Name,
+		Latitude:  *req.Latitude,
+		Longitude: *req.Longitude,
+		State:     req.State,
+		Country:   req.Country,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, location)
+}
+
+func GetLocation(c *gin.Context) {
+	location, ok := resolveLocation(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, location)
+}
+
+// resolveLocation resolves a location ID, writing the error response and
+// returning false when it cannot be resolved
+func resolveLocation(c *gin.Context, id string) (models.Location, bool) {
+	location, err := locations.Resolve(c.Request.Context(), id, c.Query("accept-language"))
+	switch {
+	case errors.Is(err, locations.ErrInvalidID):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return models.Location{}, false
+	case errors.Is(err, locations.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": "LOCATION_NOT_FOUND"})
+		return models.Location{}, false
+	case err != nil:
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return models.Location{}, false
+	}
+	return location, true
+}
+
+// queryCoordinates reads coordinates from location_id, falling back to the
+// latitude and longitude parameters
+func queryCoordinates(c *gin.Context) (string, string, bool) {
+	id := c.Query("location_id")
+	if id == "" {
+		return c.Query("latitude"), c.Query("longitude"), true
+	}
+
+	location, ok := resolveLocation(c, id)
+	if !ok {
+		return "", "", false
+	}
+	return strconv.FormatFloat(location.Latitude, 'f', -1, 64), strconv.FormatFloat(location.Longitude, 'f', -1, 64), true
+}
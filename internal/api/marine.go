@@ -0,0 +1,45 @@
+package api
+
+import (
+	"Zephyr/internal/models"
+	"Zephyr/internal/providers/openmeteo"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Marine serves wave, swell and sea level forecasts. QWeather has no wave
+// forecast, so Open-Meteo is the only source.
+func Marine(c *gin.Context) {
+	latitude := c.Query("latitude")
+	longitude := c.Query("longitude")
+	source := c.DefaultQuery("source", "om")
+
+	var marineResult models.MarineResult
+	var err error
+	switch source {
+	case "om":
+		marineResult, err = openmeteo.GetMarineDetails(c.Request.Context(), latitude, longitude)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported source"})
+		return
+	}
+	if errors.Is(err, openmeteo.ErrNoMarineData) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": err.Error(),
+			"code":  "NO_MARINE_DATA",
+		})
+		return
+	}
+	if errors.Is(err, openmeteo.ErrInvalidMarineRequest) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, marineResult)
+}
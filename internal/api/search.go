@@ -19,7 +19,7 @@ func SearchCities(c *gin.Context) {
 
 	switch source {
 	case "om":
-		resp, err := osm.SearchCitiesFromOsm(encodedQuery, acceptLanguage)
+		resp, err := osm.SearchCitiesFromOsm(c.Request.Context(), encodedQuery, acceptLanguage)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -30,10 +30,23 @@ func SearchCities(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
+
+		// Nominatim's place_id is not stable, the typed OSM ID is
+		var osmIDs []struct {
+			OsmType string `json:"osm_type"`
+			OsmID   int64  `json:"osm_id"`
+		}
+		if err := json.Unmarshal(resp, &osmIDs); err == nil && len(osmIDs) == len(places) {
+			for i := range places {
+				if id := osm.OsmID(osmIDs[i].OsmType, osmIDs[i].OsmID); id != "" {
+					places[i].LocationID = "osm:" + id
+				}
+			}
+		}
 		c.JSON(http.StatusOK, places)
 		return
 	case "qweather":
-		resp, err := qweather.SearchCitiesFromQw(encodedQuery, acceptLanguage)
+		resp, err := qweather.SearchCitiesFromQw(c.Request.Context(), encodedQuery, acceptLanguage)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"Zephyr/internal/stream"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Comment lines keep idle connections open through proxies
+const streamHeartbeatInterval = 30 * time.Second
+
+// streamHub is shared by every stream client so clients watching the same
+// location cause a single upstream poll
+var streamHub = stream.NewHub(AlertSources)
+
+// Stream pushes current conditions and alert changes over Server-Sent Events
+func Stream(c *gin.Context) {
+	lat, err1 := strconv.ParseFloat(c.Query("lat"), 64)
+	lon, err2 := strconv.ParseFloat(c.Query("lon"), 64)
+	if err1 != nil || err2 != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "valid lat and lon are required"})
+		return
+	}
+
+	subscriber := streamHub.Subscribe(stream.Location{
+		Latitude:  lat,
+		Longitude: lon,
+		Language:  c.DefaultQuery("lang", "zh"),
+		Source:    c.DefaultQuery("source", "om"),
+		Unit:      c.Query("unit"),
+	}, stream.EventCurrent, stream.EventAlerts)
+	defer subscriber.Close()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	// Disable response buffering in nginx
+	c.Header("X-Accel-Buffering", "no")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Content-Type", "text/event-stream")
+	// Send headers right away so clients see the stream open before the first event
+	c.Writer.WriteHeaderNow()
+	c.Writer.Flush()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-subscriber.Events:
+			if !ok {
+				// Dropped for falling behind, the client reconnects
+				return false
+			}
+			c.SSEvent(event.Type, event.Data)
+			return true
+		case <-heartbeat.C:
+			io.WriteString(w, ": heartbeat\n\n")
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"Zephyr/internal/models"
+	"Zephyr/internal/subscriptions"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+)
+
+type subscriptionRequest struct {
+	Latitude   *float64 `json:"latitude"`
+	Longitude  *float64 `json:"longitude"`
+	WebhookURL string   `json:"webhook_url"`
+	Language   string   `json:"language"`
+	Source     string   `json:"source"`
+}
+
+// CreateSubscription registers a webhook for alerts at a location. The
+// signing secret is only returned in this response.
+func CreateSubscription(c *gin.Context) {
+	var req subscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if req.Latitude == nil || req.Longitude == nil ||
+		*req.Latitude < -90 || *req.Latitude > 90 || *req.Longitude < -180 || *req.Longitude > 180 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "valid latitude and longitude are required"})
+		return
+	}
+
+	webhookURL, err := url.Parse(req.WebhookURL)
+	if err != nil || (webhookURL.Scheme != "http" && webhookURL.Scheme != "https") || webhookURL.Host == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "webhook_url must be an http or https URL"})
+		return
+	}
+	if err := subscriptions.CheckWebhookHost(c.Request.Context(), webhookURL.Hostname()); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "webhook_url must resolve to a public address"})
+		return
+	}
+
+	if req.Language == "" {
+		req.Language = "zh"
+	}
+
+	subscription, err := subscriptions.Create(models.Subscription{
+		Latitude:   *req.Latitude,
+		Longitude:  *req.Longitude,
+		Language:   req.Language,
+		Source:     req.Source,
+		WebhookURL: req.WebhookURL,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, subscription)
+}
+
+// GetSubscription returns a subscription without its secret
+func GetSubscription(c *gin.Context) {
+	subscription, err := subscriptions.Get(c.Param("id"))
+	if errors.Is(err, subscriptions.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	subscription.Secret = ""
+	c.JSON(http.StatusOK, subscription)
+}
+
+func DeleteSubscription(c *gin.Context) {
+	err := subscriptions.Delete(c.Param("id"))
+	if errors.Is(err, subscriptions.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
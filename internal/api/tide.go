@@ -0,0 +1,65 @@
+package api
+
+import (
+	"Zephyr/internal/models"
+	"Zephyr/internal/providers/qweather"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tide serves tide predictions for the station nearest to the coordinates,
+// or for an explicit station_id
+func Tide(c *gin.Context) {
+	latitude := c.Query("latitude")
+	longitude := c.Query("longitude")
+	stationID := c.Query("station_id")
+	language := c.Query("accept-language")
+
+	// Accept both yyyy-MM-dd and yyyyMMdd, defaulting to today at the station
+	date := strings.ReplaceAll(c.Query("date"), "-", "")
+	if date != "" {
+		if _, err := time.Parse("20060102", date); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date, expected yyyy-MM-dd"})
+			return
+		}
+	}
+
+	var station models.TideStation
+	if stationID != "" {
+		station = models.TideStation{ID: stationID}
+	} else {
+		if latitude == "" || longitude == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "latitude and longitude or station_id are required"})
+			return
+		}
+		var err error
+		station, err = qweather.FindNearestTideStation(c.Request.Context(), latitude, longitude, language)
+		if errors.Is(err, qweather.ErrNoTideStation) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+				"code":  "NO_TIDE_STATION",
+			})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if date == "" {
+		date = qweather.StationDate(station, time.Now())
+	}
+
+	tideResult, err := qweather.GetTidePredictions(c.Request.Context(), station, date, language)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tideResult)
+}
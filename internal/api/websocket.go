@@ -0,0 +1,307 @@
+package api
+
+import (
+	"Zephyr/internal/config"
+	"Zephyr/internal/logging"
+	"Zephyr/internal/stream"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+const (
+	wsWriteWait = 10 * time.Second
+	// Connections without a pong for this long are closed
+	wsPongWait     = 60 * time.Second
+	wsPingInterval = 30 * time.Second
+	// Client messages are small subscribe/unsubscribe commands
+	wsMaxMessageSize = 4096
+	// Messages queued per connection before the client is considered too slow
+	wsSendBuffer = 64
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	// The API carries no cookies, so dashboards may connect from any origin
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// wsRequest subscribes to or unsubscribes from one location and resource
+type wsRequest struct {
+	Action    string   `json:"action"`
+	Latitude  *float64 `json:"lat"`
+	Longitude *float64 `json:"lon"`
+	Resource  string   `json:"resource"`
+	Language  string   `json:"lang"`
+	Source    string   `json:"source"`
+}
+
+// wsMessage is sent to the client. Snapshot marks a full current-conditions
+// payload; later current messages only carry the fields that changed.
+type wsMessage struct {
+	Type     string      `json:"type"`
+	Topic    string      `json:"topic,omitempty"`
+	Snapshot bool        `json:"snapshot,omitempty"`
+	Data     interface{} `json:"data,omitempty"`
+	Error    string      `json:"error,omitempty"`
+}
+
+type wsConnection struct {
+	conn      *websocket.Conn
+	send      chan wsMessage
+	done      chan struct{}
+	closeOnce sync.Once
+
+	mu     sync.Mutex
+	topics map[string]*stream.Subscriber
+
+	logger *zap.Logger
+}
+
+// WebSocket lets a client watch many locations at once. Clients send
+//
+//	{"action":"subscribe","lat":39.9,"lon":116.4,"resource":"current","lang":"en","source":"om"}
+//
+// and receive messages tagged with the topic they subscribed to.
+func WebSocket(c *gin.Context) {
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade already replied with an HTTP error
+		return
+	}
+
+	ws := &wsConnection{
+		conn:   conn,
+		send:   make(chan wsMessage, wsSendBuffer),
+		done:   make(chan struct{}),
+		topics: make(map[string]*stream.Subscriber),
+		logger: logging.FromContext(c.Request.Context()),
+	}
+	defer ws.close(websocket.CloseNormalClosure, "")
+
+	go ws.writeLoop()
+	ws.readLoop()
+}
+
+func (ws *wsConnection) readLoop() {
+	ws.conn.SetReadLimit(wsMaxMessageSize)
+	ws.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	ws.conn.SetPongHandler(func(string) error {
+		return ws.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		_, data, err := ws.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req wsRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			ws.queue(wsMessage{Type: "error", Error: "invalid message"})
+			continue
+		}
+
+		switch req.Action {
+		case "subscribe":
+			ws.subscribe(req)
+		case "unsubscribe":
+			ws.unsubscribe(req)
+		default:
+			ws.queue(wsMessage{Type: "error", Error: "action must be subscribe or unsubscribe"})
+		}
+	}
+}
+
+func (ws *wsConnection) writeLoop() {
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case message := <-ws.send:
+			ws.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := ws.conn.WriteJSON(message); err != nil {
+				ws.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-ping.C:
+			if err := ws.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				ws.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-ws.done:
+			return
+		}
+	}
+}
+
+// queue hands a message to the writer. A full queue means the client cannot
+// keep up, so the connection is closed rather than buffering without bound.
+func (ws *wsConnection) queue(message wsMessage) {
+	select {
+	case ws.send <- message:
+	case <-ws.done:
+	default:
+		ws.close(websocket.ClosePolicyViolation, "client too slow")
+	}
+}
+
+func (ws *wsConnection) close(code int, reason string) {
+	ws.closeOnce.Do(func() {
+		close(ws.done)
+		if code != websocket.CloseAbnormalClosure {
+			ws.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(wsWriteWait))
+		}
+		ws.conn.Close()
+
+		ws.mu.Lock()
+		defer ws.mu.Unlock()
+		for topic, subscriber := range ws.topics {
+			delete(ws.topics, topic)
+			subscriber.Close()
+		}
+	})
+}
+
+// parseTopic validates a request and names its topic
+func parseTopic(req wsRequest) (stream.Location, string, error) {
+	if req.Latitude == nil || req.Longitude == nil ||
+		*req.Latitude < -90 || *req.Latitude > 90 || *req.Longitude < -180 || *req.Longitude > 180 {
+		return stream.Location{}, "", fmt.Errorf("valid lat and lon are required")
+	}
+	if !slices.Contains(stream.Resources, req.Resource) {
+		return stream.Location{}, "", fmt.Errorf("resource must be one of %v", stream.Resources)
+	}
+
+	location := stream.Location{
+		Latitude:  *req.Latitude,
+		Longitude: *req.Longitude,
+		Language:  req.Language,
+		Source:    req.Source,
+	}
+	if location.Language == "" {
+		location.Language = "zh"
+	}
+	if location.Source == "" {
+		location.Source = "om"
+	}
+
+	topic := fmt.Sprintf("%s:%.2f,%.2f:%s:%s", req.Resource, location.Latitude, location.Longitude,
+		location.Language, location.Source)
+	return location, topic, nil
+}
+
+func (ws *wsConnection) subscribe(req wsRequest) {
+	location, topic, err := parseTopic(req)
+	if err != nil {
+		ws.queue(wsMessage{Type: "error", Error: err.Error()})
+		return
+	}
+
+	ws.mu.Lock()
+	// A closing connection must not register topics its cleanup would miss
+	select {
+	case <-ws.done:
+		ws.mu.Unlock()
+		return
+	default:
+	}
+	if _, ok := ws.topics[topic]; ok {
+		ws.mu.Unlock()
+		ws.queue(wsMessage{Type: "subscribed", Topic: topic})
+		return
+	}
+	if len(ws.topics) >= config.WebSocketMaxTopics {
+		ws.mu.Unlock()
+		ws.queue(wsMessage{Type: "error", Topic: topic, Error: fmt.Sprintf("at most %d topics per connection", config.WebSocketMaxTopics)})
+		return
+	}
+	subscriber := streamHub.Subscribe(location, req.Resource)
+	ws.topics[topic] = subscriber
+	ws.mu.Unlock()
+
+	ws.queue(wsMessage{Type: "subscribed", Topic: topic})
+	go ws.forward(topic, subscriber)
+}
+
+func (ws *wsConnection) unsubscribe(req wsRequest) {
+	_, topic, err := parseTopic(req)
+	if err != nil {
+		ws.queue(wsMessage{Type: "error", Error: err.Error()})
+		return
+	}
+
+	ws.mu.Lock()
+	subscriber, ok := ws.topics[topic]
+	delete(ws.topics, topic)
+	ws.mu.Unlock()
+
+	if ok {
+		subscriber.Close()
+	}
+	ws.queue(wsMessage{Type: "unsubscribed", Topic: topic})
+}
+
+// forward relays hub events for one topic, reducing current conditions to
+// the fields that changed since the last message
+func (ws *wsConnection) forward(topic string, subscriber *stream.Subscriber) {
+	var last map[string]interface{}
+
+	for event := range subscriber.Events {
+		message := wsMessage{Type: event.Type, Topic: topic, Data: event.Data}
+
+		if event.Type == stream.EventCurrent {
+			fields, err := toFields(event.Data)
+			if err != nil {
+				ws.logger.Warn("Failed to encode current conditions", zap.String("topic", topic), zap.Error(err))
+				continue
+			}
+			if last == nil {
+				message.Snapshot = true
+			} else {
+				delta := make(map[string]interface{})
+				for key, value := range fields {
+					if !reflect.DeepEqual(last[key], value) {
+						delta[key] = value
+					}
+				}
+				if len(delta) == 0 {
+					continue
+				}
+				message.Data = delta
+			}
+			last = fields
+		}
+
+		ws.queue(message)
+	}
+
+	// The hub closes the channel when it drops a slow subscriber; the topic
+	// is still registered in that case, so the client has to resubscribe
+	ws.mu.Lock()
+	dropped := ws.topics[topic] == subscriber
+	ws.mu.Unlock()
+	if dropped {
+		ws.close(websocket.ClosePolicyViolation, "client too slow")
+	}
+}
+
+func toFields(data interface{}) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	err = json.Unmarshal(encoded, &fields)
+	return fields, err
+}
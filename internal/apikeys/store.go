@@ -0,0 +1,179 @@
+package apikeys
+
+import (
+	"Zephyr/internal/config"
+	"Zephyr/internal/models"
+	"Zephyr/pkg/utils"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Keys look like "zk_<id>_<secret>"; the id locates the record without
+// having to search by hash
+const keyPrefix = "zk_"
+
+var (
+	ErrNotFound      = errors.New("api key not found")
+	ErrInvalidKey    = errors.New("invalid api key")
+	ErrRevoked       = errors.New("api key has been revoked")
+	ErrQuotaExceeded = errors.New("api key quota exceeded")
+)
+
+func keyRecordKey(id string) string {
+	return fmt.Sprintf("apikey:%s", id)
+}
+
+func dailyUsageKey(id string, now time.Time) string {
+	return fmt.Sprintf("apikey:usage:%s:day:%s", id, now.Format("20060102"))
+}
+
+func monthlyUsageKey(id string, now time.Time) string {
+	return fmt.Sprintf("apikey:usage:%s:month:%s", id, now.Format("200601"))
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Create issues a new key and returns its record and the plaintext key
+func Create(name string, dailyQuota, monthlyQuota int64) (models.APIKey, string, error) {
+	id, err := utils.RandomHex(8)
+	if err != nil {
+		return models.APIKey{}, "", err
+	}
+	secret, err := utils.RandomHex(24)
+	if err != nil {
+		return models.APIKey{}, "", err
+	}
+	plaintext := keyPrefix + id + "_" + secret
+
+	apiKey := models.APIKey{
+		ID:           id,
+		Name:         name,
+		Hash:         hashKey(plaintext),
+		DailyQuota:   dailyQuota,
+		MonthlyQuota: monthlyQuota,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if err := save(apiKey); err != nil {
+		return models.APIKey{}, "", err
+	}
+	return apiKey, plaintext, nil
+}
+
+func save(apiKey models.APIKey) error {
+	data, err := json.Marshal(apiKey)
+	if err != nil {
+		return err
+	}
+	return config.RedisClient.Set(config.Ctx, keyRecordKey(apiKey.ID), data, 0).Err()
+}
+
+func Get(id string) (models.APIKey, error) {
+	data, err := config.RedisClient.Get(config.Ctx, keyRecordKey(id)).Bytes()
+	if err == redis.Nil {
+		return models.APIKey{}, ErrNotFound
+	}
+	if err != nil {
+		return models.APIKey{}, err
+	}
+
+	var apiKey models.APIKey
+	if err := json.Unmarshal(data, &apiKey); err != nil {
+		return models.APIKey{}, err
+	}
+	return apiKey, nil
+}
+
+// Revoke marks a key as revoked; the record is kept for inspection
+func Revoke(id string) (models.APIKey, error) {
+	apiKey, err := Get(id)
+	if err != nil {
+		return models.APIKey{}, err
+	}
+	if apiKey.RevokedAt == nil {
+		now := time.Now().UTC()
+		apiKey.RevokedAt = &now
+		if err := save(apiKey); err != nil {
+			return models.APIKey{}, err
+		}
+	}
+	return apiKey, nil
+}
+
+// Authenticate looks up a plaintext key and checks it is valid and active
+func Authenticate(key string) (models.APIKey, error) {
+	rest, found := strings.CutPrefix(key, keyPrefix)
+	if !found {
+		return models.APIKey{}, ErrInvalidKey
+	}
+	id, _, found := strings.Cut(rest, "_")
+	if !found || id == "" {
+		return models.APIKey{}, ErrInvalidKey
+	}
+
+	apiKey, err := Get(id)
+	if errors.Is(err, ErrNotFound) {
+		return models.APIKey{}, ErrInvalidKey
+	}
+	if err != nil {
+		return models.APIKey{}, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashKey(key)), []byte(apiKey.Hash)) != 1 {
+		return models.APIKey{}, ErrInvalidKey
+	}
+	if apiKey.RevokedAt != nil {
+		return models.APIKey{}, ErrRevoked
+	}
+	return apiKey, nil
+}
+
+// Consume counts one request against the key's quotas. The request is
+// counted even when rejected, so hammering an exhausted key stays rejected.
+func Consume(apiKey models.APIKey) (models.APIKeyUsage, error) {
+	now := time.Now().UTC()
+	dailyKey, monthlyKey := dailyUsageKey(apiKey.ID, now), monthlyUsageKey(apiKey.ID, now)
+
+	pipe := config.RedisClient.TxPipeline()
+	dailyCmd := pipe.Incr(config.Ctx, dailyKey)
+	pipe.Expire(config.Ctx, dailyKey, 48*time.Hour)
+	monthlyCmd := pipe.Incr(config.Ctx, monthlyKey)
+	pipe.Expire(config.Ctx, monthlyKey, 32*24*time.Hour)
+	if _, err := pipe.Exec(config.Ctx); err != nil {
+		return models.APIKeyUsage{}, err
+	}
+
+	usage := models.APIKeyUsage{Daily: dailyCmd.Val(), Monthly: monthlyCmd.Val()}
+	if (apiKey.DailyQuota > 0 && usage.Daily > apiKey.DailyQuota) ||
+		(apiKey.MonthlyQuota > 0 && usage.Monthly > apiKey.MonthlyQuota) {
+		return usage, ErrQuotaExceeded
+	}
+	return usage, nil
+}
+
+// Usage returns the current day's and month's request counts
+func Usage(id string) (models.APIKeyUsage, error) {
+	now := time.Now().UTC()
+	pipe := config.RedisClient.Pipeline()
+	dailyCmd := pipe.Get(config.Ctx, dailyUsageKey(id, now))
+	monthlyCmd := pipe.Get(config.Ctx, monthlyUsageKey(id, now))
+	// Missing counters just mean no requests yet
+	if _, err := pipe.Exec(config.Ctx); err != nil && err != redis.Nil {
+		return models.APIKeyUsage{}, err
+	}
+
+	var usage models.APIKeyUsage
+	usage.Daily, _ = dailyCmd.Int64()
+	usage.Monthly, _ = monthlyCmd.Int64()
+	return usage, nil
+}
@@ -0,0 +1,124 @@
+package bans
+
+import (
+	"Zephyr/internal/config"
+	"Zephyr/internal/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Sorted set of banned IPs scored by expiry, used to list active bans
+const bansKey = "bans"
+
+var ErrNotFound = errors.New("ban not found")
+
+func banKey(ip string) string {
+	return fmt.Sprintf("ban:%s", ip)
+}
+
+func offencesKey(ip string) string {
+	return fmt.Sprintf("ban:offences:%s", ip)
+}
+
+// duration doubles the base ban for every earlier offence, up to the maximum
+func duration(offences int64) time.Duration {
+	d := config.BanBaseDuration
+	for i := int64(1); i < offences && d < config.BanMaxDuration; i++ {
+		d *= 2
+	}
+	return min(d, config.BanMaxDuration)
+}
+
+// Ban blocks an IP. Repeat offenders within the history window are banned
+// for longer each time.
+func Ban(ip, reason string) (models.Ban, error) {
+	offences, err := config.RedisClient.Incr(config.Ctx, offencesKey(ip)).Result()
+	if err != nil {
+		return models.Ban{}, err
+	}
+
+	now := time.Now().UTC()
+	ban := models.Ban{
+		IP:        ip,
+		Reason:    reason,
+		Offences:  offences,
+		CreatedAt: now,
+		ExpiresAt: now.Add(duration(offences)),
+	}
+	data, err := json.Marshal(ban)
+	if err != nil {
+		return models.Ban{}, err
+	}
+
+	pipe := config.RedisClient.TxPipeline()
+	pipe.Expire(config.Ctx, offencesKey(ip), config.BanHistory)
+	pipe.Set(config.Ctx, banKey(ip), data, ban.ExpiresAt.Sub(now))
+	pipe.ZAdd(config.Ctx, bansKey, &redis.Z{Score: float64(ban.ExpiresAt.Unix()), Member: ip})
+	if _, err := pipe.Exec(config.Ctx); err != nil {
+		return models.Ban{}, err
+	}
+	return ban, nil
+}
+
+// Get returns the active ban of an IP
+func Get(ip string) (models.Ban, error) {
+	data, err := config.RedisClient.Get(config.Ctx, banKey(ip)).Bytes()
+	if err == redis.Nil {
+		return models.Ban{}, ErrNotFound
+	}
+	if err != nil {
+		return models.Ban{}, err
+	}
+
+	var ban models.Ban
+	if err := json.Unmarshal(data, &ban); err != nil {
+		return models.Ban{}, err
+	}
+	return ban, nil
+}
+
+// List returns all active bans, dropping expired entries from the index
+func List() ([]models.Ban, error) {
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	if err := config.RedisClient.ZRemRangeByScore(config.Ctx, bansKey, "-inf", now).Err(); err != nil {
+		return nil, err
+	}
+	ips, err := config.RedisClient.ZRange(config.Ctx, bansKey, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	bans := []models.Ban{}
+	for _, ip := range ips {
+		ban, err := Get(ip)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		bans = append(bans, ban)
+	}
+	return bans, nil
+}
+
+// Lift removes a ban and forgets earlier offences, as lifting usually
+// means the ban was a mistake
+func Lift(ip string) error {
+	pipe := config.RedisClient.TxPipeline()
+	deleted := pipe.Del(config.Ctx, banKey(ip))
+	pipe.Del(config.Ctx, offencesKey(ip))
+	pipe.ZRem(config.Ctx, bansKey, ip)
+	if _, err := pipe.Exec(config.Ctx); err != nil {
+		return err
+	}
+	if deleted.Val() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+// Package cache stores provider responses in Redis as JSON. Lookups and
+// stores are logged with the key family, the part of the key before the
+// first colon such as "weather" or "tide", hits and misses are counted per
+// family and every lookup and store gets a span.
+package cache
+
+import (
+	"Zephyr/internal/config"
+	"Zephyr/internal/logging"
+	"Zephyr/internal/metrics"
+	"Zephyr/internal/tracing"
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type bypassKey struct{}
+
+// Bypass returns a context whose lookups always miss, so callers fetch fresh
+// data and store it for everyone else
+func Bypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey{}, true)
+}
+
+func family(key string) string {
+	family, _, _ := strings.Cut(key, ":")
+	return family
+}
+
+func startSpan(ctx context.Context, name, provider, key string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNameRedis,
+			attribute.String("provider", provider),
+			attribute.String("cache.family", family(key)),
+			attribute.String("cache.key", key),
+		),
+	)
+}
+
+// Get loads the value cached under key into target and reports whether it
+// was found. Redis errors and corrupt entries count as misses.
+func Get(ctx context.Context, provider, key string, target any) bool {
+	ctx, span := startSpan(ctx, "cache.get", provider, key)
+	defer span.End()
+
+	if ctx.Value(bypassKey{}) != nil {
+		span.SetAttributes(attribute.Bool("cache.hit", false), attribute.Bool("cache.bypass", true))
+		metrics.CacheRequests.WithLabelValues(family(key), "bypass").Inc()
+		return false
+	}
+
+	start := time.Now()
+	data, err := config.RedisClient.Get(config.Ctx, key).Bytes()
+	logger := logging.FromContext(ctx).With(
+		zap.String("provider", provider),
+		zap.String("cache", family(key)),
+		zap.String("key", key),
+		zap.Duration("latency", time.Since(start)),
+	)
+	if err != nil {
+		if err != redis.Nil {
+			logger.Warn("Cache lookup failed", zap.Error(err))
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.SetAttributes(attribute.Bool("cache.hit", false))
+		metrics.CacheRequests.WithLabelValues(family(key), "miss").Inc()
+		return false
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		logger.Warn("Discarding corrupt cache entry", zap.Error(err))
+		span.SetAttributes(attribute.Bool("cache.hit", false))
+		metrics.CacheRequests.WithLabelValues(family(key), "miss").Inc()
+		return false
+	}
+
+	span.SetAttributes(attribute.Bool("cache.hit", true))
+	metrics.CacheRequests.WithLabelValues(family(key), "hit").Inc()
+	logger.Info("Retrieved from cache")
+	return true
+}
+
+// Set caches value under key for ttl. Caching is best effort, so failures
+// are only logged.
+func Set(ctx context.Context, provider, key string, value any, ttl time.Duration) {
+	ctx, span := startSpan(ctx, "cache.set", provider, key)
+	defer span.End()
+
+	logger := logging.FromContext(ctx).With(
+		zap.String("provider", provider),
+		zap.String("cache", family(key)),
+		zap.String("key", key),
+	)
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		logger.Warn("Failed to encode cache entry", zap.Error(err))
+		return
+	}
+	start := time.Now()
+	if err := config.RedisClient.Set(config.Ctx, key, data, ttl).Err(); err != nil {
+		logger.Warn("Cache store failed", zap.Error(err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+	logger.Info("Cached", zap.Duration("latency", time.Since(start)))
+}
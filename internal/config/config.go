@@ -3,9 +3,9 @@ package config
 import (
 	"Zephyr/internal/models"
 	"context"
-	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -22,6 +22,72 @@ var (
 	QweatherConfig models.QweatherConfig
 	QweatherUrl    string
 
+	// CAP 1.2 / ATOM alert feeds (URLs or file paths) merged with QWeather warnings
+	CapFeedUrls []string
+
+	// Alert subscription configuration
+	AlertPollInterval  time.Duration
+	WebhookMaxAttempts int
+	WebhookTimeout     time.Duration
+	// Private networks webhooks may still reach
+	WebhookAllowedNetworks []string
+
+	// Push notification configuration, a platform is enabled once configured
+	FcmEndpoint        string
+	FcmCredentialsFile string
+	ApnsEndpoint       string
+	ApnsKeyID          string
+	ApnsTeamID         string
+	ApnsTopic          string
+	PushWorkers        int
+	ApnsPrivateKey     string
+
+	// How often locations watched by stream clients are refreshed
+	StreamRefreshInterval time.Duration
+	WebSocketMaxTopics    int
+
+	// Batch forecast limits
+	BatchMaxLocations int
+	BatchWorkers      int
+
+	// API key authentication
+	RequireAPIKey bool
+	AdminToken    string
+
+	// Per API key rate limit on /api/v1 routes
+	RateLimitRequests int
+	RateLimitWindow   time.Duration
+
+	// Proxies allowed to report the client address
+	TrustedProxies     []string
+	TrustedProxyHeader string
+	ProxyProtocol      bool
+
+	// IP allow/deny lists and anomaly bans
+	IPAllowList      []string
+	IPDenyList       []string
+	BanBaseDuration  time.Duration
+	BanMaxDuration   time.Duration
+	BanHistory       time.Duration
+	AnomalyThreshold int
+	AnomalyWindow    time.Duration
+
+	// HMAC request signing for first-party clients
+	SigningKeys      []string
+	SignatureMaxSkew time.Duration
+
+	// Logging; upstream payloads are only logged at debug level
+	LogLevel                 string
+	UpstreamLogSamplePercent int
+	UpstreamLogMaxBytes      int
+
+	// Bearer token required to scrape /metrics; empty leaves it open
+	MetricsToken string
+
+	// OTLP/HTTP collector for traces; empty disables export
+	OTLPEndpoint       string
+	TraceSamplePercent int
+
 	// Server configuration
 	ServerPort string
 	EnableTLS  bool
@@ -29,13 +95,12 @@ var (
 	KeyFile    string
 )
 
-// LoadConfig loads configuration from .env file
-func LoadConfig() {
+// LoadConfig loads configuration from .env file. It runs before logging is
+// set up, so a missing .env file is returned for the caller to log; the
+// environment and defaults are loaded either way.
+func LoadConfig() error {
 	// Load .env file
-	err := godotenv.Load()
-	if err != nil {
-		log.Println("Warning: .env file not found, using environment variables or defaults")
-	}
+	envErr := godotenv.Load()
 
 	// Redis configuration
 	RedisAddr = getEnv("REDIS_ADDR", "127.0.0.1:6379")
@@ -47,19 +112,99 @@ func LoadConfig() {
 	CacheTTL = time.Duration(cacheTTLMinutes) * time.Minute
 
 	// QWeather configuration
-	QweatherConfig = models.QweatherConfig{
-		ProjectID:     getEnv("QWEATHER_PROJECT_ID", ""),
-		KeyID:         getEnv("QWEATHER_KEY_ID", ""),
-		PrivateKeyPem: getEnv("QWEATHER_PRIVATE_KEY", ""),
-	}
+	QweatherConfig = ReadQweatherConfig()
 
 	QweatherUrl = getEnv("QWEATHER_URL", "")
 
+	// Alert feed configuration
+	CapFeedUrls = getEnvList("CAP_FEED_URLS")
+
+	// Alert subscription configuration
+	AlertPollInterval = time.Duration(getEnvInt("ALERT_POLL_INTERVAL_MINUTES", 5)) * time.Minute
+	WebhookMaxAttempts = getEnvInt("WEBHOOK_MAX_ATTEMPTS", 5)
+	WebhookTimeout = time.Duration(getEnvInt("WEBHOOK_TIMEOUT_SECONDS", 10)) * time.Second
+	WebhookAllowedNetworks = getEnvList("WEBHOOK_ALLOWED_NETWORKS")
+
+	// Push notification configuration
+	FcmEndpoint = getEnv("FCM_ENDPOINT", "https://fcm.googleapis.com")
+	FcmCredentialsFile = getEnv("FCM_CREDENTIALS_FILE", "")
+	ApnsEndpoint = getEnv("APNS_ENDPOINT", "https://api.push.apple.com")
+	ApnsKeyID = getEnv("APNS_KEY_ID", "")
+	ApnsTeamID = getEnv("APNS_TEAM_ID", "")
+	ApnsTopic = getEnv("APNS_TOPIC", "")
+	ApnsPrivateKey = getEnv("APNS_PRIVATE_KEY", "")
+	PushWorkers = getEnvInt("PUSH_WORKERS", 16)
+
+	// Live stream configuration
+	StreamRefreshInterval = time.Duration(getEnvInt("STREAM_REFRESH_SECONDS", 60)) * time.Second
+	WebSocketMaxTopics = getEnvInt("WS_MAX_TOPICS", 50)
+
+	// Batch forecast configuration
+	BatchMaxLocations = getEnvInt("BATCH_MAX_LOCATIONS", 50)
+	BatchWorkers = getEnvInt("BATCH_WORKERS", 8)
+
+	// API key configuration
+	RequireAPIKey = getEnvBool("REQUIRE_API_KEY", true)
+	AdminToken = getEnv("ADMIN_TOKEN", "")
+
+	// Rate limit configuration
+	RateLimitRequests = getEnvInt("RATE_LIMIT_REQUESTS", 60)
+	RateLimitWindow = time.Duration(getEnvInt("RATE_LIMIT_WINDOW_SECONDS", 60)) * time.Second
+
+	// Client IP resolution behind load balancers
+	TrustedProxies = getEnvList("TRUSTED_PROXIES")
+	TrustedProxyHeader = getEnv("TRUSTED_PROXY_HEADER", "x-forwarded-for")
+	ProxyProtocol = getEnvBool("PROXY_PROTOCOL", false)
+
+	// IP filtering
+	IPAllowList = getEnvList("IP_ALLOW_LIST")
+	IPDenyList = getEnvList("IP_DENY_LIST")
+	BanBaseDuration = time.Duration(getEnvInt("BAN_BASE_MINUTES", 15)) * time.Minute
+	BanMaxDuration = time.Duration(getEnvInt("BAN_MAX_HOURS", 24)) * time.Hour
+	BanHistory = time.Duration(getEnvInt("BAN_HISTORY_DAYS", 7)) * 24 * time.Hour
+	AnomalyThreshold = getEnvInt("ANOMALY_THRESHOLD", 600)
+	AnomalyWindow = time.Duration(getEnvInt("ANOMALY_WINDOW_MINUTES", 5)) * time.Minute
+
+	// Request signing
+	SigningKeys = getEnvList("SIGNING_KEYS")
+	SignatureMaxSkew = time.Duration(getEnvInt("SIGNATURE_MAX_SKEW_SECONDS", 300)) * time.Second
+
+	// Logging
+	LogLevel = getEnv("LOG_LEVEL", "info")
+	UpstreamLogSamplePercent = getEnvInt("UPSTREAM_LOG_SAMPLE_PERCENT", 10)
+	UpstreamLogMaxBytes = getEnvInt("UPSTREAM_LOG_MAX_BYTES", 2048)
+
+	// Metrics
+	MetricsToken = getEnv("METRICS_TOKEN", "")
+
+	// Tracing
+	OTLPEndpoint = getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	TraceSamplePercent = getEnvInt("TRACE_SAMPLE_PERCENT", 100)
+
 	// Server configuration
 	ServerPort = getEnv("SERVER_PORT", ":3899")
 	EnableTLS = getEnvBool("ENABLE_TLS", true)
 	CertFile = getEnv("CERT_FILE", "./cert/zephyr.claret.space_bundle.crt")
 	KeyFile = getEnv("KEY_FILE", "./cert/zephyr.claret.space.key")
+
+	return envErr
+}
+
+// ReadQweatherConfig reads the QWeather credentials from the environment
+func ReadQweatherConfig() models.QweatherConfig {
+	return models.QweatherConfig{
+		ProjectID:      getEnv("QWEATHER_PROJECT_ID", ""),
+		KeyID:          getEnv("QWEATHER_KEY_ID", ""),
+		PrivateKeyPem:  getEnv("QWEATHER_PRIVATE_KEY", ""),
+		PrivateKeyFile: getEnv("QWEATHER_PRIVATE_KEY_FILE", ""),
+		KeyFiles:       getEnvList("QWEATHER_KEY_FILES"),
+	}
+}
+
+// ReloadEnv re-reads the .env file, overriding values loaded at startup.
+// Only settings that are re-read afterwards, like the QWeather keys, change.
+func ReloadEnv() error {
+	return godotenv.Overload()
 }
 
 // getEnv gets environment variable with default value
@@ -90,8 +235,20 @@ func getEnvBool(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
-// InitRedis initializes Redis client
-func InitRedis() {
+// getEnvList gets a comma separated environment variable as a list
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// InitRedis initializes Redis client and returns the result of a test ping.
+// The client is usable either way and reconnects on later commands.
+func InitRedis() error {
 	RedisClient = redis.NewClient(&redis.Options{
 		Addr:     RedisAddr,
 		Password: RedisPassword,
@@ -99,10 +256,5 @@ func InitRedis() {
 	})
 
 	// Test Redis connection
-	_, err := RedisClient.Ping(Ctx).Result()
-	if err != nil {
-		log.Printf("Failed to connect to Redis: %v", err)
-	} else {
-		log.Println("Successfully connected to Redis")
-	}
+	return RedisClient.Ping(Ctx).Err()
 }
@@ -4,9 +4,15 @@ const (
 	// OsmUrl for search city info
 	OsmUrl = "https://nominatim.openstreetmap.org/search"
 
+	// OsmLookupUrl for resolving OSM IDs
+	OsmLookupUrl = "https://nominatim.openstreetmap.org/lookup"
+
 	// OmForcastUrl for weather forecast
 	OmForcastUrl = "https://api.open-meteo.com/v1/forecast"
 
 	// OmAirQualityUrl for air quality
 	OmAirQualityUrl = "https://air-quality-api.open-meteo.com/v1/air-quality"
+
+	// OmMarineUrl for wave, swell and sea level forecast
+	OmMarineUrl = "https://marine-api.open-meteo.com/v1/marine"
 )
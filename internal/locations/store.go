@@ -0,0 +1,132 @@
+package locations
+
+import (
+	"Zephyr/internal/config"
+	"Zephyr/internal/logging"
+	"Zephyr/internal/models"
+	osm "Zephyr/internal/providers/openstreetmap"
+	"Zephyr/internal/providers/qweather"
+	"Zephyr/pkg/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// Provider places rarely move; re-resolve them monthly
+const providerCacheTTL = 30 * 24 * time.Hour
+
+var (
+	ErrNotFound  = errors.New("location not found")
+	ErrInvalidID = errors.New("invalid location_id, expected qw:<id>, osm:<N|W|R><id> or zeus:<id>")
+
+	qweatherIDPattern = regexp.MustCompile(`^[0-9A-Za-z]+$`)
+	osmIDPattern      = regexp.MustCompile(`^[NWR][0-9]+$`)
+	zeusIDPattern     = regexp.MustCompile(`^[0-9a-f]+$`)
+)
+
+func locationKey(id string) string {
+	return fmt.Sprintf("location:%s", id)
+}
+
+// resolveKey adds the language to provider IDs, whose names are localised
+// by the provider. Zeus places are stored once as issued.
+func resolveKey(id, language string) string {
+	if strings.HasPrefix(id, "zeus:") {
+		return locationKey(id)
+	}
+	return fmt.Sprintf("location:%s:%s", id, strings.ToLower(language))
+}
+
+// normalizeID adds the qw: prefix to bare QWeather IDs and validates the format
+func normalizeID(id string) (string, error) {
+	scheme, value, found := strings.Cut(strings.TrimSpace(id), ":")
+	if !found {
+		scheme, value = "qw", scheme
+	}
+
+	switch scheme {
+	case "qw":
+		if !qweatherIDPattern.MatchString(value) {
+			return "", ErrInvalidID
+		}
+	case "osm":
+		value = strings.ToUpper(value)
+		if !osmIDPattern.MatchString(value) {
+			return "", ErrInvalidID
+		}
+	case "zeus":
+		if !zeusIDPattern.MatchString(value) {
+			return "", ErrInvalidID
+		}
+	default:
+		return "", ErrInvalidID
+	}
+	return scheme + ":" + value, nil
+}
+
+// Resolve returns the place behind a location ID, asking the issuing
+// provider on a cache miss. Zeus IDs only exist in the store.
+func Resolve(ctx context.Context, id, language string) (models.Location, error) {
+	id, err := normalizeID(id)
+	if err != nil {
+		return models.Location{}, err
+	}
+
+	cacheKey := resolveKey(id, language)
+	if cachedData, err := config.RedisClient.Get(config.Ctx, cacheKey).Result(); err == nil {
+		var location models.Location
+		if err := json.Unmarshal([]byte(cachedData), &location); err == nil {
+			logging.FromContext(ctx).Info("Retrieved location from cache", zap.String("key", cacheKey))
+			return location, nil
+		}
+	} else if err != redis.Nil {
+		return models.Location{}, err
+	}
+
+	scheme, value, _ := strings.Cut(id, ":")
+	var location models.Location
+	var found bool
+	switch scheme {
+	case "qw":
+		location, found, err = qweather.LookupLocation(ctx, value, language)
+	case "osm":
+		location, found, err = osm.LookupPlace(ctx, value, language)
+	}
+	if err != nil {
+		return models.Location{}, err
+	}
+	if !found {
+		return models.Location{}, ErrNotFound
+	}
+
+	if cachedData, err := json.Marshal(location); err == nil {
+		logging.FromContext(ctx).Info("Cached location", zap.String("key", cacheKey))
+		config.RedisClient.Set(config.Ctx, cacheKey, cachedData, providerCacheTTL)
+	}
+	return location, nil
+}
+
+// Issue stores a place under a new Zeus ID that never expires
+func Issue(location models.Location) (models.Location, error) {
+	value, err := utils.RandomHex(8)
+	if err != nil {
+		return models.Location{}, err
+	}
+	location.ID = "zeus:" + value
+
+	data, err := json.Marshal(location)
+	if err != nil {
+		return models.Location{}, err
+	}
+	if err := config.RedisClient.Set(config.Ctx, locationKey(location.ID), data, 0).Err(); err != nil {
+		return models.Location{}, err
+	}
+	return location, nil
+}
@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"Zephyr/internal/config"
+	"context"
+	"fmt"
+	"math/rand/v2"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// logger is replaced by Init; until then nothing is logged through it
+var logger = zap.NewNop()
+
+// Init builds the production logger at LOG_LEVEL. Standard library log
+// output is redirected through it so every line is redacted.
+func Init() error {
+	level, err := zapcore.ParseLevel(config.LogLevel)
+	if err != nil {
+		return fmt.Errorf("invalid LOG_LEVEL %q: %w", config.LogLevel, err)
+	}
+
+	cfg := zap.NewProductionConfig()
+	cfg.Level = zap.NewAtomicLevelAt(level)
+	l, err := cfg.Build(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &redactingCore{core}
+	}))
+	if err != nil {
+		return err
+	}
+	SetLogger(l)
+	return nil
+}
+
+// New builds a redacting JSON logger writing to ws, mainly for tests
+func New(ws zapcore.WriteSyncer, level zapcore.Level) *zap.Logger {
+	encoder := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	return zap.New(&redactingCore{zapcore.NewCore(encoder, ws, level)})
+}
+
+// SetLogger replaces the shared logger and redirects the standard library
+// logger to it
+func SetLogger(l *zap.Logger) {
+	logger = l
+	zap.RedirectStdLog(l)
+}
+
+// L returns the shared logger
+func L() *zap.Logger {
+	return logger
+}
+
+// UpstreamPayload logs a provider response body at debug level only. Bodies
+// are sampled, truncated to UPSTREAM_LOG_MAX_BYTES and redacted.
+func UpstreamPayload(ctx context.Context, provider, url string, body []byte) {
+	logger := FromContext(ctx)
+	if !logger.Core().Enabled(zapcore.DebugLevel) || rand.IntN(100) >= config.UpstreamLogSamplePercent {
+		return
+	}
+
+	size := len(body)
+	truncated := size > config.UpstreamLogMaxBytes
+	if truncated {
+		body = body[:config.UpstreamLogMaxBytes]
+	}
+	logger.Debug("Upstream response",
+		zap.String("provider", provider),
+		zap.String("url", url),
+		zap.Int("size", size),
+		zap.Bool("truncated", truncated),
+		zap.ByteString("body", body),
+	)
+}
@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const redacted = "[REDACTED]"
+
+// Patterns of credentials that must never reach the logs. Matching is
+// deliberately broad; an over-redacted line is preferable to a leaked key.
+var redactions = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	// Authorization header values of any scheme
+	{regexp.MustCompile(`(?i)(authorization["']?\s*[:=]\s*["']?)[^"'\r\n,}]+`), "${1}" + redacted},
+	{regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9\-._~+/]+=*`), "${1}" + redacted},
+	// JWTs, such as QWeather tokens
+	{regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`), redacted},
+	// Zephyr API keys
+	{regexp.MustCompile(`zk_[A-Za-z0-9]+_[A-Za-z0-9]+`), "zk_" + redacted},
+	// Credentials in query strings, JSON and key=value pairs
+	{regexp.MustCompile(`(?i)\b((?:api_?key|access_token|token|secret|password|private_key|signature)["']?\s*[=:]\s*["']?)[^&\s"',}]+`), "${1}" + redacted},
+	// PEM encoded keys
+	{regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`), redacted},
+}
+
+// Redact removes credentials from s
+func Redact(s string) string {
+	for _, r := range redactions {
+		s = r.pattern.ReplaceAllString(s, r.replacement)
+	}
+	return s
+}
+
+// redactingCore redacts the message and fields of every entry before it is
+// encoded
+type redactingCore struct {
+	zapcore.Core
+}
+
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{c.Core.With(redactFields(fields))}
+}
+
+func (c *redactingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *redactingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = Redact(entry.Message)
+	return c.Core.Write(entry, redactFields(fields))
+}
+
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	result := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		switch f.Type {
+		case zapcore.StringType:
+			f.String = Redact(f.String)
+		case zapcore.ByteStringType:
+			f = zap.String(f.Key, Redact(string(f.Interface.([]byte))))
+		case zapcore.ErrorType:
+			f = zap.String(f.Key, Redact(f.Interface.(error).Error()))
+		case zapcore.StringerType:
+			f = zap.String(f.Key, Redact(fmt.Sprint(f.Interface)))
+		case zapcore.ReflectType:
+			if data, err := json.Marshal(f.Interface); err == nil {
+				f = zap.String(f.Key, Redact(string(data)))
+			}
+		}
+		result[i] = f
+	}
+	return result
+}
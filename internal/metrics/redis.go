@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisHook counts failed Redis commands. A missing key is not a failure.
+type RedisHook struct{}
+
+func (RedisHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return ctx, nil
+}
+
+func (RedisHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	countRedisError(cmd)
+	return nil
+}
+
+func (RedisHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return ctx, nil
+}
+
+func (RedisHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	for _, cmd := range cmds {
+		countRedisError(cmd)
+	}
+	return nil
+}
+
+func countRedisError(cmd redis.Cmder) {
+	if err := cmd.Err(); err != nil && err != redis.Nil {
+		RedisErrors.WithLabelValues(cmd.Name()).Inc()
+	}
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"Zephyr/internal/bans"
+	"Zephyr/internal/logging"
+	"Zephyr/internal/ratelimit"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// AnomalyConfig sets how many requests a single IP may send across all
+// routes within Window before it is banned
+type AnomalyConfig struct {
+	Threshold int
+	Window    time.Duration
+}
+
+// BanAnomalies counts every request per client IP, including those later
+// rejected by rate limits, and bans clients that exceed the threshold. It
+// must run after IPFilter, so banned clients are not counted, and before
+// any limiter. A zero threshold disables it.
+func BanAnomalies(cfg AnomalyConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := ClientIP(c)
+		if cfg.Threshold <= 0 || IsAllowListed(ip) {
+			c.Next()
+			return
+		}
+
+		logger := logging.FromContext(c.Request.Context())
+		key := fmt.Sprintf("anomaly:%s", ip)
+		result, err := ratelimit.Allow(key, cfg.Threshold, cfg.Window)
+		if err != nil {
+			// Like rate limiting, let requests through when Redis fails
+			logger.Warn("Anomaly check failed", zap.String("ip", ip), zap.Error(err))
+			c.Next()
+			return
+		}
+		if result.Allowed {
+			c.Next()
+			return
+		}
+
+		logger.Error("Anomalous access detected",
+			zap.String("ip", ip),
+			zap.Int("request_count", result.Limit-result.Remaining),
+			zap.Duration("window", cfg.Window),
+			zap.String("path", c.Request.URL.Path),
+			zap.String("user_agent", c.GetHeader("User-Agent")),
+		)
+
+		// Later requests are rejected by IPFilter until the ban expires
+		ban, err := bans.Ban(ip, "anomalous access")
+		if err != nil {
+			logger.Error("Failed to ban anomalous client", zap.String("ip", ip), zap.Error(err))
+		} else {
+			logger.Warn("Client banned",
+				zap.String("ip", ip),
+				zap.Int64("offences", ban.Offences),
+				zap.Time("expires_at", ban.ExpiresAt),
+			)
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error": "Anomalous access detected, request rejected",
+			"code":  "ANOMALOUS_ACCESS_DETECTED",
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"Zephyr/internal/bans"
+	"Zephyr/internal/config"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+)
+
+// useTestRedis points the shared client at an in-memory Redis for the test
+func useTestRedis(t *testing.T) *miniredis.Miniredis {
+	t.Helper()
+	server := miniredis.RunT(t)
+	previous := config.RedisClient
+	config.RedisClient = redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() {
+		config.RedisClient.Close()
+		config.RedisClient = previous
+	})
+	return server
+}
+
+func useBanDurations(t *testing.T) {
+	t.Helper()
+	base, maximum, history := config.BanBaseDuration, config.BanMaxDuration, config.BanHistory
+	config.BanBaseDuration, config.BanMaxDuration, config.BanHistory = 15*time.Minute, 24*time.Hour, 7*24*time.Hour
+	t.Cleanup(func() {
+		config.BanBaseDuration, config.BanMaxDuration, config.BanHistory = base, maximum, history
+	})
+}
+
+// floodRouter mirrors the middleware order of the server: IP filter,
+// anomaly bans, then a rate limit far below the anomaly threshold
+func floodRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(IPFilter(), BanAnomalies(AnomalyConfig{Threshold: 5, Window: 5 * time.Minute}))
+	limited := RateLimit(RateLimitConfig{Name: "test", Limit: 2, Window: time.Minute, KeyFunc: ByIP})
+	r.GET("/api/v1/healthcheck", limited, func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/api/v1/weather/forecast", limited, func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+// flood sends count requests from ip across two routes and returns the
+// status codes
+func flood(r *gin.Engine, ip string, count int) []int {
+	var codes []int
+	paths := []string{"/api/v1/healthcheck", "/api/v1/weather/forecast"}
+	for i := range count {
+		req := httptest.NewRequest(http.MethodGet, paths[i%len(paths)], nil)
+		req.RemoteAddr = ip + ":4000"
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		codes = append(codes, w.Code)
+	}
+	return codes
+}
+
+func TestFloodingClientIsBannedAndEscalated(t *testing.T) {
+	server := useTestRedis(t)
+	useBanDurations(t)
+	r := floodRouter()
+	const ip = "203.0.113.7"
+
+	// Rate limited requests still count, so the sixth request is anomalous
+	codes := flood(r, ip, 7)
+	want := []int{200, 200, 429, 429, 429, 403, 403}
+	for i := range want {
+		if codes[i] != want[i] {
+			t.Fatalf("status codes = %v, want %v", codes, want)
+		}
+	}
+
+	first, err := bans.Get(ip)
+	if err != nil {
+		t.Fatalf("bans.Get = %v, want an active ban", err)
+	}
+	if first.Offences != 1 || first.ExpiresAt.Sub(first.CreatedAt) != 15*time.Minute {
+		t.Errorf("first ban = %d offences for %v, want 1 for 15m", first.Offences, first.ExpiresAt.Sub(first.CreatedAt))
+	}
+
+	// Once the ban and the window expire, a second flood doubles the ban
+	server.FastForward(16 * time.Minute)
+	if _, err := bans.Get(ip); !errors.Is(err, bans.ErrNotFound) {
+		t.Fatalf("bans.Get after expiry = %v, want ErrNotFound", err)
+	}
+	flood(r, ip, 6)
+	second, err := bans.Get(ip)
+	if err != nil {
+		t.Fatalf("bans.Get = %v, want a second ban", err)
+	}
+	if second.Offences != 2 || second.ExpiresAt.Sub(second.CreatedAt) != 30*time.Minute {
+		t.Errorf("second ban = %d offences for %v, want 2 for 30m", second.Offences, second.ExpiresAt.Sub(second.CreatedAt))
+	}
+}
+
+func TestAllowListedClientIsNeverBanned(t *testing.T) {
+	useTestRedis(t)
+	useBanDurations(t)
+	previousAllow, previousDeny := allowList, denyList
+	t.Cleanup(func() { allowList, denyList = previousAllow, previousDeny })
+	if err := SetIPLists([]string{"198.51.100.0/24"}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	const ip = "198.51.100.9"
+	for _, code := range flood(floodRouter(), ip, 10) {
+		if code == http.StatusForbidden {
+			t.Fatal("allow-listed client was rejected as anomalous")
+		}
+	}
+	if _, err := bans.Get(ip); !errors.Is(err, bans.ErrNotFound) {
+		t.Errorf("bans.Get = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"Zephyr/internal/apikeys"
+	"Zephyr/internal/config"
+	"Zephyr/internal/logging"
+	"Zephyr/internal/metrics"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// APIKeyIDContextKey holds the authenticated key's ID in the gin context
+const APIKeyIDContextKey = "api_key_id"
+
+// APIKeyQueryParam carries the key for clients that cannot set headers. It
+// must be stripped from any URL echoed back in a response.
+const APIKeyQueryParam = "api_key"
+
+// requestAPIKey reads the key from the Authorization or X-API-Key header.
+// The api_key query parameter is accepted for EventSource and browser
+// WebSocket clients, which cannot set headers.
+func requestAPIKey(c *gin.Context) string {
+	if key, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); found {
+		return strings.TrimSpace(key)
+	}
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return key
+	}
+	return c.Query(APIKeyQueryParam)
+}
+
+// RequireAPIKey authenticates the request and counts it against the key's
+// daily and monthly quotas
+func RequireAPIKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !config.RequireAPIKey {
+			c.Next()
+			return
+		}
+
+		key := requestAPIKey(c)
+		if key == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "API key required",
+				"code":  "API_KEY_REQUIRED",
+			})
+			return
+		}
+
+		apiKey, err := apikeys.Authenticate(key)
+		switch {
+		case errors.Is(err, apikeys.ErrInvalidKey), errors.Is(err, apikeys.ErrRevoked):
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": err.Error(),
+				"code":  "INVALID_API_KEY",
+			})
+			return
+		case err != nil:
+			// Keys cannot be verified without Redis, so fail closed
+			logging.FromContext(c.Request.Context()).Error("Failed to authenticate API key", zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"error": "authentication temporarily unavailable",
+				"code":  "AUTH_UNAVAILABLE",
+			})
+			return
+		}
+		c.Set(APIKeyIDContextKey, apiKey.ID)
+
+		usage, err := apikeys.Consume(apiKey)
+		if apiKey.DailyQuota > 0 {
+			c.Header("X-Quota-Daily-Limit", strconv.FormatInt(apiKey.DailyQuota, 10))
+			c.Header("X-Quota-Daily-Remaining", strconv.FormatInt(max(apiKey.DailyQuota-usage.Daily, 0), 10))
+		}
+		if apiKey.MonthlyQuota > 0 {
+			c.Header("X-Quota-Monthly-Limit", strconv.FormatInt(apiKey.MonthlyQuota, 10))
+			c.Header("X-Quota-Monthly-Remaining", strconv.FormatInt(max(apiKey.MonthlyQuota-usage.Monthly, 0), 10))
+		}
+		switch {
+		case errors.Is(err, apikeys.ErrQuotaExceeded):
+			metrics.RateLimitRejections.WithLabelValues("quota").Inc()
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error": err.Error(),
+				"code":  "QUOTA_EXCEEDED",
+			})
+			return
+		case err != nil:
+			// Quota accounting is best effort, like the health check rate limit
+			logging.FromContext(c.Request.Context()).Warn("Failed to count API key usage", zap.Error(err))
+		}
+
+		c.Next()
+	}
+}
+
+// RequireAdmin protects admin routes with the static ADMIN_TOKEN. Admin
+// routes are disabled when no token is configured.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if config.AdminToken == "" || !found ||
+			subtle.ConstantTimeCompare([]byte(token), []byte(config.AdminToken)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "admin token required",
+				"code":  "ADMIN_TOKEN_REQUIRED",
+			})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"Zephyr/pkg/utils"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Resolved client IP cached in the gin context
+const clientIPContextKey = "client_ip"
+
+// Forwarding headers a trusted proxy may report the client address in
+const (
+	HeaderForwarded     = "forwarded"
+	HeaderXForwardedFor = "x-forwarded-for"
+	HeaderNone          = "none"
+)
+
+var (
+	// trustedProxies is set once at startup by SetTrustedProxies
+	trustedProxies []*net.IPNet
+	// proxyHeader is set once at startup by SetProxyHeader
+	proxyHeader = HeaderXForwardedFor
+)
+
+// SetTrustedProxies parses CIDRs and plain IPs of the proxies allowed to
+// report client addresses
+func SetTrustedProxies(proxies []string) error {
+	networks, err := utils.ParseNetworks(proxies)
+	if err != nil {
+		return err
+	}
+	trustedProxies = networks
+	return nil
+}
+
+// SetProxyHeader selects the only header read from trusted proxies. Proxies
+// append to one header and pass any other through from the client, so
+// reading both would let clients pick their own address.
+func SetProxyHeader(header string) error {
+	switch header = strings.ToLower(strings.TrimSpace(header)); header {
+	case HeaderForwarded, HeaderXForwardedFor, HeaderNone:
+		proxyHeader = header
+		return nil
+	}
+	return fmt.Errorf("unknown proxy header %q, expected forwarded, x-forwarded-for or none", header)
+}
+
+// IsTrustedProxy reports whether the address belongs to a trusted proxy
+func IsTrustedProxy(ip net.IP) bool {
+	return utils.ContainsIP(trustedProxies, ip)
+}
+
+// ClientIP returns the address of the client. Forwarding headers are only
+// honoured when the peer is a trusted proxy, and are read right to left so
+// that entries a client prepends itself are never reached.
+func ClientIP(c *gin.Context) string {
+	if ip := c.GetString(clientIPContextKey); ip != "" {
+		return ip
+	}
+	ip := resolveClientIP(c.Request)
+	c.Set(clientIPContextKey, ip)
+	return ip
+}
+
+func resolveClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	remote := net.ParseIP(host)
+	if remote == nil || !IsTrustedProxy(remote) {
+		return host
+	}
+
+	// Only the configured header is read, the other may be client supplied
+	var hops []string
+	switch proxyHeader {
+	case HeaderForwarded:
+		hops = forwardedHops(r.Header.Values("Forwarded"))
+	case HeaderXForwardedFor:
+		for _, value := range r.Header.Values("X-Forwarded-For") {
+			hops = append(hops, strings.Split(value, ",")...)
+		}
+	}
+
+	// Walk back from the nearest hop; the first untrusted address is the
+	// client. An unparseable hop ends the chain at the last trusted proxy.
+	client := remote
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(stripPort(strings.TrimSpace(hops[i])))
+		if ip == nil {
+			break
+		}
+		client = ip
+		if !IsTrustedProxy(ip) {
+			break
+		}
+	}
+	return client.String()
+}
+
+// forwardedHops extracts the for= parameters of RFC 7239 Forwarded headers
+func forwardedHops(values []string) []string {
+	var hops []string
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			for _, pair := range strings.Split(element, ";") {
+				key, node, found := strings.Cut(strings.TrimSpace(pair), "=")
+				if found && strings.EqualFold(key, "for") {
+					hops = append(hops, strings.Trim(node, `"`))
+				}
+			}
+		}
+	}
+	return hops
+}
+
+// stripPort removes a port from "1.2.3.4:80" or "[2001:db8::1]:80" and the
+// brackets from "[2001:db8::1]"
+func stripPort(node string) string {
+	if host, _, err := net.SplitHostPort(node); err == nil {
+		return host
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(node, "["), "]")
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+)
+
+func useTrustedProxies(t *testing.T, proxies []string, header string) {
+	t.Helper()
+	previousProxies, previousHeader := trustedProxies, proxyHeader
+	t.Cleanup(func() {
+		trustedProxies, proxyHeader = previousProxies, previousHeader
+	})
+	if err := SetTrustedProxies(proxies); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetProxyHeader(header); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolveClientIP(t *testing.T) {
+	cases := []struct {
+		name    string
+		header  string
+		remote  string
+		headers map[string][]string
+		want    string
+	}{
+		{
+			name:    "untrusted peer ignores headers",
+			header:  HeaderXForwardedFor,
+			remote:  "203.0.113.7:4000",
+			headers: map[string][]string{"X-Forwarded-For": {"1.2.3.4"}},
+			want:    "203.0.113.7",
+		},
+		{
+			name:    "trusted peer without header",
+			header:  HeaderXForwardedFor,
+			remote:  "10.0.0.1:4000",
+			headers: nil,
+			want:    "10.0.0.1",
+		},
+		{
+			name:    "client prepended entry is never reached",
+			header:  HeaderXForwardedFor,
+			remote:  "10.0.0.1:4000",
+			headers: map[string][]string{"X-Forwarded-For": {"1.2.3.4, 198.51.100.9"}},
+			want:    "198.51.100.9",
+		},
+		{
+			name:    "trusted hops are skipped",
+			header:  HeaderXForwardedFor,
+			remote:  "10.0.0.1:4000",
+			headers: map[string][]string{"X-Forwarded-For": {"1.2.3.4, 198.51.100.9, 10.0.0.2"}},
+			want:    "198.51.100.9",
+		},
+		{
+			name:    "repeated headers are joined in order",
+			header:  HeaderXForwardedFor,
+			remote:  "10.0.0.1:4000",
+			headers: map[string][]string{"X-Forwarded-For": {"1.2.3.4", "198.51.100.9"}},
+			want:    "198.51.100.9",
+		},
+		{
+			name:    "unparseable hop stops at the last trusted proxy",
+			header:  HeaderXForwardedFor,
+			remote:  "10.0.0.1:4000",
+			headers: map[string][]string{"X-Forwarded-For": {"1.2.3.4, garbage, 10.0.0.2"}},
+			want:    "10.0.0.2",
+		},
+		{
+			name:   "forwarded header is ignored when x-forwarded-for is trusted",
+			header: HeaderXForwardedFor,
+			remote: "10.0.0.1:4000",
+			headers: map[string][]string{
+				"Forwarded":       {"for=1.2.3.4"},
+				"X-Forwarded-For": {"198.51.100.9"},
+			},
+			want: "198.51.100.9",
+		},
+		{
+			name:    "forwarded header alone is ignored when x-forwarded-for is trusted",
+			header:  HeaderXForwardedFor,
+			remote:  "10.0.0.1:4000",
+			headers: map[string][]string{"Forwarded": {"for=1.2.3.4"}},
+			want:    "10.0.0.1",
+		},
+		{
+			name:   "x-forwarded-for is ignored when forwarded is trusted",
+			header: HeaderForwarded,
+			remote: "10.0.0.1:4000",
+			headers: map[string][]string{
+				"Forwarded":       {`for="[2001:db8::5]:443";proto=https`},
+				"X-Forwarded-For": {"1.2.3.4"},
+			},
+			want: "2001:db8::5",
+		},
+		{
+			name:    "forwarded walks right to left",
+			header:  HeaderForwarded,
+			remote:  "10.0.0.1:4000",
+			headers: map[string][]string{"Forwarded": {"for=1.2.3.4, for=198.51.100.9:80"}},
+			want:    "198.51.100.9",
+		},
+		{
+			name:   "none reads no header",
+			header: HeaderNone,
+			remote: "10.0.0.1:4000",
+			headers: map[string][]string{
+				"Forwarded":       {"for=1.2.3.4"},
+				"X-Forwarded-For": {"1.2.3.4"},
+			},
+			want: "10.0.0.1",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			useTrustedProxies(t, []string{"10.0.0.0/8"}, c.header)
+			r := &http.Request{RemoteAddr: c.remote, Header: http.Header(c.headers)}
+			if got := resolveClientIP(r); got != c.want {
+				t.Errorf("resolveClientIP = %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestSetProxyHeader(t *testing.T) {
+	useTrustedProxies(t, nil, HeaderNone)
+	for _, header := range []string{"forwarded", "X-Forwarded-For", " none "} {
+		if err := SetProxyHeader(header); err != nil {
+			t.Errorf("SetProxyHeader(%q) = %v, want nil", header, err)
+		}
+	}
+	if err := SetProxyHeader("x-real-ip"); err == nil {
+		t.Error("SetProxyHeader(\"x-real-ip\") = nil, want an error")
+	}
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"Zephyr/internal/bans"
+	"Zephyr/internal/logging"
+	"Zephyr/pkg/utils"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Static lists, set once at startup by SetIPLists
+var allowList, denyList []*net.IPNet
+
+// SetIPLists parses the static allow and deny lists of CIDRs and plain IPs
+func SetIPLists(allow, deny []string) error {
+	allowNetworks, err := utils.ParseNetworks(allow)
+	if err != nil {
+		return err
+	}
+	denyNetworks, err := utils.ParseNetworks(deny)
+	if err != nil {
+		return err
+	}
+	allowList, denyList = allowNetworks, denyNetworks
+	return nil
+}
+
+// IsAllowListed reports whether an IP skips deny lists and bans
+func IsAllowListed(ip string) bool {
+	parsed := net.ParseIP(ip)
+	return parsed != nil && utils.ContainsIP(allowList, parsed)
+}
+
+func isDenyListed(ip string) bool {
+	parsed := net.ParseIP(ip)
+	return parsed != nil && utils.ContainsIP(denyList, parsed)
+}
+
+// IPFilter rejects denied and banned clients. Allow-listed clients are
+// never blocked, and the static deny list takes precedence over bans.
+func IPFilter() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := ClientIP(c)
+		if IsAllowListed(ip) {
+			c.Next()
+			return
+		}
+		if isDenyListed(ip) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "Access denied",
+				"code":  "IP_DENIED",
+			})
+			return
+		}
+
+		ban, err := bans.Get(ip)
+		switch {
+		case errors.Is(err, bans.ErrNotFound):
+			c.Next()
+		case err != nil:
+			// Like rate limiting, let requests through when Redis fails
+			logging.FromContext(c.Request.Context()).Warn("Failed to check ban", zap.String("ip", ip), zap.Error(err))
+			c.Next()
+		default:
+			retryAfter := max(int(time.Until(ban.ExpiresAt).Seconds()), 1)
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":      "Access temporarily banned",
+				"code":       "IP_BANNED",
+				"expires_at": ban.ExpiresAt,
+			})
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"Zephyr/internal/config"
+	"Zephyr/internal/metrics"
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics records the count and latency of every request. Routes are the
+// registered patterns, so path parameters do not multiply the series.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+		metrics.HTTPRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// MetricsHandler serves the Prometheus metrics. When METRICS_TOKEN is set
+// scrapers must send it as a bearer token.
+func MetricsHandler() gin.HandlerFunc {
+	handler := promhttp.Handler()
+	return func(c *gin.Context) {
+		if config.MetricsToken != "" {
+			token, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+			if !found || subtle.ConstantTimeCompare([]byte(token), []byte(config.MetricsToken)) != 1 {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+					"error": "metrics token required",
+					"code":  "METRICS_TOKEN_REQUIRED",
+				})
+				return
+			}
+		}
+		handler.ServeHTTP(c.Writer, c.Request)
+	}
+}
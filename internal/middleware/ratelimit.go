@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"Zephyr/internal/logging"
+	"Zephyr/internal/metrics"
+	"Zephyr/internal/ratelimit"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RateLimitConfig configures one limiter. Name separates the counters of
+// different route groups; KeyFunc decides who is being limited.
+type RateLimitConfig struct {
+	Name    string
+	Limit   int
+	Window  time.Duration
+	KeyFunc func(c *gin.Context) string
+}
+
+// ByIP limits each client IP
+func ByIP(c *gin.Context) string {
+	return "ip:" + ClientIP(c)
+}
+
+// ByAPIKey limits each authenticated API key, falling back to the client IP
+// for unauthenticated requests
+func ByAPIKey(c *gin.Context) string {
+	if id := c.GetString(APIKeyIDContextKey); id != "" {
+		return "key:" + id
+	}
+	return ByIP(c)
+}
+
+// RateLimit rejects requests beyond Limit per sliding Window and reports
+// the limiter state in the RateLimit-* headers
+func RateLimit(cfg RateLimitConfig) gin.HandlerFunc {
+	policy := fmt.Sprintf("%d;w=%d", cfg.Limit, int(cfg.Window.Seconds()))
+
+	return func(c *gin.Context) {
+		key := fmt.Sprintf("rate_limit:%s:%s", cfg.Name, cfg.KeyFunc(c))
+		result, err := ratelimit.Allow(key, cfg.Limit, cfg.Window)
+		if err != nil {
+			// When Redis fails, allow requests to pass for safety
+			logging.FromContext(c.Request.Context()).Warn("Rate limit check failed", zap.String("key", key), zap.Error(err))
+			c.Next()
+			return
+		}
+
+		reset := strconv.Itoa(int(math.Ceil(result.Reset.Seconds())))
+		c.Header("RateLimit-Policy", policy)
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", reset)
+
+		if !result.Allowed {
+			metrics.RateLimitRejections.WithLabelValues(cfg.Name).Inc()
+			c.Header("Retry-After", reset)
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error": "Too many requests, please try again later",
+				"code":  "RATE_LIMIT_EXCEEDED",
+			})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func rateLimitedRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RateLimit(RateLimitConfig{Name: "test", Limit: 2, Window: time.Minute, KeyFunc: ByIP}))
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+func get(r *gin.Engine, ip string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = ip + ":4000"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	useTestRedis(t)
+	r := rateLimitedRouter()
+
+	cases := []struct {
+		code       int
+		remaining  string
+		retryAfter string
+	}{
+		{http.StatusOK, "1", ""},
+		{http.StatusOK, "0", ""},
+		{http.StatusTooManyRequests, "0", "60"},
+	}
+	for i, c := range cases {
+		w := get(r, "203.0.113.7")
+		if w.Code != c.code {
+			t.Fatalf("request %d: status = %d, want %d", i+1, w.Code, c.code)
+		}
+		headers := map[string]string{
+			"RateLimit-Policy":    "2;w=60",
+			"RateLimit-Limit":     "2",
+			"RateLimit-Remaining": c.remaining,
+			"RateLimit-Reset":     "60",
+			"Retry-After":         c.retryAfter,
+		}
+		for name, want := range headers {
+			if got := w.Header().Get(name); got != want {
+				t.Errorf("request %d: %s = %q, want %q", i+1, name, got, want)
+			}
+		}
+	}
+
+	// Other clients have their own window
+	if w := get(r, "198.51.100.9"); w.Code != http.StatusOK {
+		t.Errorf("other client: status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestRateLimitFailsOpen(t *testing.T) {
+	server := useTestRedis(t)
+	server.Close()
+	r := rateLimitedRouter()
+
+	for i := range 3 {
+		w := get(r, "203.0.113.7")
+		if w.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want %d when Redis is down", i+1, w.Code, http.StatusOK)
+		}
+		if got := w.Header().Get("RateLimit-Limit"); got != "" {
+			t.Errorf("request %d: RateLimit-Limit = %q, want no header without a result", i+1, got)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"Zephyr/internal/logging"
+	"Zephyr/pkg/utils"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	// RequestIDContextKey holds the request ID in the gin context
+	RequestIDContextKey = "request_id"
+)
+
+// Incoming IDs are reused only when they cannot break log lines or headers
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID accepts the caller's X-Request-ID or generates one, and stores a
+// logger tagged with the request ID, route and trace ID in the request context. Each
+// request is logged once it completes.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		id := c.GetHeader(RequestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id, _ = utils.RandomHex(16)
+		}
+		c.Set(RequestIDContextKey, id)
+		c.Header(RequestIDHeader, id)
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		logger := logging.L().With(zap.String("request_id", id), zap.String("route", route))
+		// Log lines can be matched with the request's trace
+		if spanContext := trace.SpanContextFromContext(c.Request.Context()); spanContext.HasTraceID() {
+			logger = logger.With(zap.String("trace_id", spanContext.TraceID().String()))
+		}
+		c.Request = c.Request.WithContext(logging.NewContext(c.Request.Context(), logger))
+
+		c.Next()
+
+		level := zapcore.InfoLevel
+		if c.Writer.Status() >= 500 {
+			level = zapcore.ErrorLevel
+		}
+		logger.Log(level, "Request completed",
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.Request.URL.RequestURI()),
+			zap.Int("status", c.Writer.Status()),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("ip", ClientIP(c)),
+			zap.Int("size", c.Writer.Size()),
+		)
+	}
+}
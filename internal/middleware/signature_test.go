@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"Zephyr/internal/config"
+	"Zephyr/internal/signing"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// signedRequest builds a request signed with secret, then applies tamper
+// to the finished request
+func signedRequest(secret, keyID, nonce string, at time.Time, body string, tamper func(*http.Request)) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/devices?platform=ios", strings.NewReader(body))
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	canonical := signing.CanonicalRequest(r, keyID, timestamp, nonce, []byte(body))
+	r.Header.Set(signing.KeyIDHeader, keyID)
+	r.Header.Set(signing.TimestampHeader, timestamp)
+	r.Header.Set(signing.NonceHeader, nonce)
+	r.Header.Set(signing.SignatureHeader, signing.Sign([]byte(secret), canonical))
+	if tamper != nil {
+		tamper(r)
+	}
+	return r
+}
+
+func TestVerifySignature(t *testing.T) {
+	useTestRedis(t)
+	previousKeys, previousSkew := signingKeys, config.SignatureMaxSkew
+	t.Cleanup(func() { signingKeys, config.SignatureMaxSkew = previousKeys, previousSkew })
+	if err := SetSigningKeys([]string{"ios:secret"}); err != nil {
+		t.Fatal(err)
+	}
+	config.SignatureMaxSkew = 5 * time.Minute
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(VerifySignature())
+	r.POST("/api/v1/devices", func(c *gin.Context) {
+		// The handler still sees the body that was hashed
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, "%t %s", IsSigned(c), body)
+	})
+
+	const body = `{"token":"abc"}`
+	now := time.Now()
+	cases := []struct {
+		name    string
+		request *http.Request
+		code    int
+		errCode string
+	}{
+		{"valid", signedRequest("secret", "ios", "n-valid", now, body, nil), http.StatusOK, ""},
+		{"within clock skew", signedRequest("secret", "ios", "n-skew", now.Add(-4*time.Minute), body, nil), http.StatusOK, ""},
+		{"tampered body", signedRequest("secret", "ios", "n-body", now, body, func(r *http.Request) {
+			r.Body = io.NopCloser(strings.NewReader(`{"token":"xyz"}`))
+		}), http.StatusUnauthorized, "INVALID_SIGNATURE"},
+		{"tampered query", signedRequest("secret", "ios", "n-query", now, body, func(r *http.Request) {
+			r.URL.RawQuery = "platform=fcm"
+		}), http.StatusUnauthorized, "INVALID_SIGNATURE"},
+		{"wrong secret", signedRequest("other", "ios", "n-secret", now, body, nil), http.StatusUnauthorized, "INVALID_SIGNATURE"},
+		{"unknown key", signedRequest("secret", "android", "n-key", now, body, nil), http.StatusUnauthorized, "INVALID_SIGNATURE"},
+		{"missing nonce", signedRequest("secret", "ios", "", now, body, nil), http.StatusUnauthorized, "INVALID_SIGNATURE"},
+		{"stale timestamp", signedRequest("secret", "ios", "n-stale", now.Add(-6*time.Minute), body, nil), http.StatusUnauthorized, "SIGNATURE_EXPIRED"},
+		{"future timestamp", signedRequest("secret", "ios", "n-future", now.Add(6*time.Minute), body, nil), http.StatusUnauthorized, "SIGNATURE_EXPIRED"},
+		{"reused nonce", signedRequest("secret", "ios", "n-valid", now, body, nil), http.StatusUnauthorized, "REPLAYED_REQUEST"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, c.request)
+			if w.Code != c.code {
+				t.Fatalf("status = %d, want %d: %s", w.Code, c.code, w.Body)
+			}
+			if c.errCode != "" && !strings.Contains(w.Body.String(), `"code":"`+c.errCode+`"`) {
+				t.Errorf("body = %s, want code %s", w.Body, c.errCode)
+			}
+			if c.code == http.StatusOK && w.Body.String() != "true "+body {
+				t.Errorf("handler saw %q, want a signed request with the original body", w.Body)
+			}
+		})
+	}
+}
+
+func TestUnsignedRequestsPassThrough(t *testing.T) {
+	previousKeys := signingKeys
+	t.Cleanup(func() { signingKeys = previousKeys })
+	if err := SetSigningKeys([]string{"ios:secret"}); err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(VerifySignature())
+	r.GET("/", func(c *gin.Context) { c.String(http.StatusOK, "%t", IsSigned(c)) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "false" {
+		t.Errorf("unsigned request = %d %q, want 200 and unsigned", w.Code, w.Body)
+	}
+}
+
+func TestVerifySignatureFailsClosedWithoutRedis(t *testing.T) {
+	server := useTestRedis(t)
+	server.Close()
+	previousKeys, previousSkew := signingKeys, config.SignatureMaxSkew
+	t.Cleanup(func() { signingKeys, config.SignatureMaxSkew = previousKeys, previousSkew })
+	if err := SetSigningKeys([]string{"ios:secret"}); err != nil {
+		t.Fatal(err)
+	}
+	config.SignatureMaxSkew = 5 * time.Minute
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(VerifySignature())
+	r.POST("/api/v1/devices", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, signedRequest("secret", "ios", "n-1", time.Now(), "{}", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d when nonces cannot be recorded", w.Code, http.StatusServiceUnavailable)
+	}
+}
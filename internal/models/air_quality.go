@@ -0,0 +1,85 @@
+package models
+
+// Pollutants holds concentrations in µg/m³
+type Pollutants struct {
+	Pm25            float64 `json:"pm2_5"`
+	Pm10            float64 `json:"pm10"`
+	Ozone           float64 `json:"ozone"`
+	NitrogenDioxide float64 `json:"nitrogen_dioxide"`
+	SulfurDioxide   float64 `json:"sulfur_dioxide"`
+	CarbonMonoxide  float64 `json:"carbon_monoxide"`
+}
+
+// ProviderAQI is the index exactly as reported by the upstream provider
+type ProviderAQI struct {
+	Scale    string  `json:"scale"`
+	Value    float64 `json:"value"`
+	Category string  `json:"category,omitempty"`
+}
+
+// AQIIndex is an index computed server-side from pollutant concentrations
+type AQIIndex struct {
+	Value            int    `json:"value"`
+	Category         string `json:"category"`
+	HealthAdvice     string `json:"health_advice"`
+	PrimaryPollutant string `json:"primary_pollutant,omitempty"`
+}
+
+// PollenConcentrations holds pollen counts in grains/m³
+type PollenConcentrations struct {
+	Alder   float64 `json:"alder"`
+	Birch   float64 `json:"birch"`
+	Grass   float64 `json:"grass"`
+	Mugwort float64 `json:"mugwort"`
+	Olive   float64 `json:"olive"`
+	Ragweed float64 `json:"ragweed"`
+}
+
+type CurrentAirQualityResult struct {
+	Time             string              `json:"time"`
+	Pollutants       Pollutants          `json:"pollutants"`
+	PrimaryPollutant string              `json:"primary_pollutant,omitempty"`
+	ProviderIndex    ProviderAQI         `json:"provider_index"`
+	Indices          map[string]AQIIndex `json:"indices,omitempty"`
+}
+
+type HourlyAirQualityResult struct {
+	Time             string                `json:"time"`
+	Pollutants       Pollutants            `json:"pollutants"`
+	PrimaryPollutant string                `json:"primary_pollutant,omitempty"`
+	ProviderIndex    ProviderAQI           `json:"provider_index"`
+	Indices          map[string]AQIIndex   `json:"indices,omitempty"`
+	Pollen           *PollenConcentrations `json:"pollen,omitempty"`
+}
+
+type DailyAirQualityResult struct {
+	Date             string              `json:"date"`
+	Pollutants       Pollutants          `json:"pollutants"`
+	PrimaryPollutant string              `json:"primary_pollutant,omitempty"`
+	ProviderIndex    ProviderAQI         `json:"provider_index"`
+	Indices          map[string]AQIIndex `json:"indices,omitempty"`
+}
+
+// PollenSpecies is the daily peak of a single pollen species
+type PollenSpecies struct {
+	Species       string  `json:"species"`
+	Concentration float64 `json:"concentration"`
+	Level         string  `json:"level"`
+}
+
+// DailyPollenResult rates the allergy risk of a day. Species are only
+// available from concentration based sources; index based sources fill Text.
+type DailyPollenResult struct {
+	Date      string          `json:"date"`
+	Risk      string          `json:"risk"`
+	RiskLevel int             `json:"risk_level"`
+	Species   []PollenSpecies `json:"species,omitempty"`
+	Text      string          `json:"text,omitempty"`
+}
+
+type AirQualityResult struct {
+	Current CurrentAirQualityResult  `json:"current"`
+	Hourly  []HourlyAirQualityResult `json:"hourly"`
+	Daily   []DailyAirQualityResult  `json:"daily"`
+	Pollen  []DailyPollenResult      `json:"pollen,omitempty"`
+}
@@ -0,0 +1,82 @@
+package models
+
+import "time"
+
+// AlertSeverity follows the CAP 1.2 <severity> values
+type AlertSeverity string
+
+const (
+	SeverityExtreme  AlertSeverity = "Extreme"
+	SeveritySevere   AlertSeverity = "Severe"
+	SeverityModerate AlertSeverity = "Moderate"
+	SeverityMinor    AlertSeverity = "Minor"
+	SeverityUnknown  AlertSeverity = "Unknown"
+)
+
+// AlertUrgency follows the CAP 1.2 <urgency> values
+type AlertUrgency string
+
+const (
+	UrgencyImmediate AlertUrgency = "Immediate"
+	UrgencyExpected  AlertUrgency = "Expected"
+	UrgencyFuture    AlertUrgency = "Future"
+	UrgencyPast      AlertUrgency = "Past"
+	UrgencyUnknown   AlertUrgency = "Unknown"
+)
+
+// AlertCertainty follows the CAP 1.2 <certainty> values
+type AlertCertainty string
+
+const (
+	CertaintyObserved AlertCertainty = "Observed"
+	CertaintyLikely   AlertCertainty = "Likely"
+	CertaintyPossible AlertCertainty = "Possible"
+	CertaintyUnlikely AlertCertainty = "Unlikely"
+	CertaintyUnknown  AlertCertainty = "Unknown"
+)
+
+// AlertColor is the warning color used by most national services
+type AlertColor string
+
+const (
+	ColorWhite   AlertColor = "white"
+	ColorGreen   AlertColor = "green"
+	ColorBlue    AlertColor = "blue"
+	ColorYellow  AlertColor = "yellow"
+	ColorOrange  AlertColor = "orange"
+	ColorRed     AlertColor = "red"
+	ColorBlack   AlertColor = "black"
+	ColorUnknown AlertColor = "unknown"
+)
+
+// AlertArea is the affected area. Polygons hold [latitude, longitude]
+// vertices and are only present when the source publishes geometry.
+type AlertArea struct {
+	Description string         `json:"description,omitempty"`
+	Polygons    [][][2]float64 `json:"polygons,omitempty"`
+}
+
+// Alert is a weather warning normalized across alert sources.
+// Status is "active", "update" or "cancel".
+type Alert struct {
+	ID          string         `json:"id"`
+	Source      string         `json:"source"`
+	Sender      string         `json:"sender"`
+	Event       string         `json:"event"`
+	Headline    string         `json:"headline"`
+	Description string         `json:"description"`
+	Language    string         `json:"language"`
+	Severity    AlertSeverity  `json:"severity"`
+	Urgency     AlertUrgency   `json:"urgency"`
+	Certainty   AlertCertainty `json:"certainty"`
+	Color       AlertColor     `json:"color"`
+	Status      string         `json:"status"`
+	Sent        time.Time      `json:"sent"`
+	Onset       *time.Time     `json:"onset,omitempty"`
+	Expires     *time.Time     `json:"expires,omitempty"`
+	Area        *AlertArea     `json:"area,omitempty"`
+}
+
+type AlertResult struct {
+	Alerts []Alert `json:"alerts"`
+}
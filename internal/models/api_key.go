@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// APIKey describes an issued key. Only the SHA-256 hash of the key is
+// stored; the plaintext is returned once when the key is created.
+// A quota of 0 means unlimited.
+type APIKey struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	Hash         string     `json:"hash,omitempty"`
+	DailyQuota   int64      `json:"daily_quota"`
+	MonthlyQuota int64      `json:"monthly_quota"`
+	CreatedAt    time.Time  `json:"created_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+}
+
+// APIKeyUsage counts requests in the current UTC day and month
+type APIKeyUsage struct {
+	Daily   int64 `json:"daily"`
+	Monthly int64 `json:"monthly"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Ban blocks an IP until ExpiresAt. Offences counts the bans within the
+// history window and decides how long the next one lasts.
+type Ban struct {
+	IP        string    `json:"ip"`
+	Reason    string    `json:"reason"`
+	Offences  int64     `json:"offences"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
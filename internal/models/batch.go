@@ -0,0 +1,21 @@
+package models
+
+type Coordinates struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// BatchForecastItem holds either the forecast or the error for one location.
+// ID echoes the client's identifier for the location, if any.
+type BatchForecastItem struct {
+	ID        string         `json:"id,omitempty"`
+	Latitude  float64        `json:"latitude"`
+	Longitude float64        `json:"longitude"`
+	Forecast  *WeatherResult `json:"forecast,omitempty"`
+	Error     string         `json:"error,omitempty"`
+}
+
+// BatchForecastResult lists results in request order
+type BatchForecastResult struct {
+	Results []BatchForecastItem `json:"results"`
+}
@@ -1,10 +1,13 @@
 package models
 
+// FilteredSearchResult carries a location_id that can be passed to the
+// forecast, alert and air endpoints instead of coordinates
 type FilteredSearchResult struct {
-	Name    string `json:"name"`
-	Lat     string `json:"lat"`
-	Lon     string `json:"lon"`
-	Address struct {
+	Name       string `json:"name"`
+	Lat        string `json:"lat"`
+	Lon        string `json:"lon"`
+	LocationID string `json:"location_id,omitempty"`
+	Address    struct {
 		State   string `json:"state"`
 		Country string `json:"country"`
 	} `json:"address"`
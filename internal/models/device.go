@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Push platforms a device can register with
+const (
+	PlatformFCM  = "fcm"
+	PlatformAPNs = "apns"
+)
+
+// Device is an app installation registered for push notifications at a
+// location. Language selects the language of alerts and notification text.
+type Device struct {
+	Token     string    `json:"token"`
+	Platform  string    `json:"platform"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	Language  string    `json:"language"`
+	Source    string    `json:"source"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+// LifestyleIndex is a daily activity or lifestyle rating. Type is a stable
+// key shared by all sources; Name, Category and Text are display strings.
+// Computed marks indices derived by Zeus rather than reported by the provider.
+type LifestyleIndex struct {
+	Date     string `json:"date"`
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Level    int    `json:"level"`
+	Category string `json:"category"`
+	Text     string `json:"text"`
+	Computed bool   `json:"computed"`
+}
@@ -0,0 +1,13 @@
+package models
+
+// Location is a place resolved from a location ID. IDs carry their issuer as
+// a prefix: "qw:" for QWeather, "osm:" for OpenStreetMap and "zeus:" for IDs
+// issued by this service.
+type Location struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	State     string  `json:"state,omitempty"`
+	Country   string  `json:"country,omitempty"`
+}
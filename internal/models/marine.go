@@ -0,0 +1,31 @@
+package models
+
+// HourlyMarineResult heights are in metres, periods in seconds, directions in
+// degrees, sea surface temperature in °C. SeaLevelHeight is relative to mean
+// sea level and includes tides.
+type HourlyMarineResult struct {
+	Time                  string  `json:"time"`
+	WaveHeight            float64 `json:"wave_height"`
+	WaveDirection         float64 `json:"wave_direction"`
+	WavePeriod            float64 `json:"wave_period"`
+	WindWaveHeight        float64 `json:"wind_wave_height"`
+	SwellWaveHeight       float64 `json:"swell_wave_height"`
+	SwellWaveDirection    float64 `json:"swell_wave_direction"`
+	SwellWavePeriod       float64 `json:"swell_wave_period"`
+	SeaSurfaceTemperature float64 `json:"sea_surface_temperature"`
+	SeaLevelHeight        float64 `json:"sea_level_height"`
+}
+
+type DailyMarineResult struct {
+	Date                  string  `json:"date"`
+	WaveHeightMax         float64 `json:"wave_height_max"`
+	WaveDirectionDominant float64 `json:"wave_direction_dominant"`
+	WavePeriodMax         float64 `json:"wave_period_max"`
+	SwellWaveHeightMax    float64 `json:"swell_wave_height_max"`
+	SwellWavePeriodMax    float64 `json:"swell_wave_period_max"`
+}
+
+type MarineResult struct {
+	Hourly []HourlyMarineResult `json:"hourly"`
+	Daily  []DailyMarineResult  `json:"daily"`
+}
@@ -0,0 +1,12 @@
+package models
+
+// MinutelyPrecipitation is precipitation in mm over the 15 minutes starting at Time (UTC)
+type MinutelyPrecipitation struct {
+	Time          string  `json:"time"`
+	Precipitation float64 `json:"precipitation"`
+}
+
+// NowcastResult covers the next two hours in 15 minute steps
+type NowcastResult struct {
+	Minutely []MinutelyPrecipitation `json:"minutely"`
+}
@@ -1,7 +1,11 @@
 package models
 
 type QweatherConfig struct {
-	KeyID         string
-	ProjectID     string
-	PrivateKeyPem string
+	KeyID          string
+	ProjectID      string
+	PrivateKeyPem  string
+	PrivateKeyFile string
+	// Additional "kid:path" keys, so the active KeyID can be switched to a
+	// key that is already loaded and verified
+	KeyFiles []string
 }
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Subscription registers a webhook for alerts at a location. Secret signs
+// webhook payloads and is only returned when the subscription is created.
+type Subscription struct {
+	ID         string    `json:"id"`
+	Latitude   float64   `json:"latitude"`
+	Longitude  float64   `json:"longitude"`
+	Language   string    `json:"language"`
+	Source     string    `json:"source"`
+	WebhookURL string    `json:"webhook_url"`
+	Secret     string    `json:"secret,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Alert event types delivered to subscribers
+const (
+	AlertEventNew       = "new"
+	AlertEventUpdated   = "updated"
+	AlertEventCancelled = "cancelled"
+)
+
+type AlertEvent struct {
+	Type  string `json:"type"`
+	Alert Alert  `json:"alert"`
+}
+
+// WebhookPayload is the body POSTed to a subscription's webhook
+type WebhookPayload struct {
+	SubscriptionID string       `json:"subscription_id"`
+	SentAt         time.Time    `json:"sent_at"`
+	Events         []AlertEvent `json:"events"`
+}
@@ -0,0 +1,30 @@
+package models
+
+type TideStation struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	Latitude   float64 `json:"latitude"`
+	Longitude  float64 `json:"longitude"`
+	DistanceKm float64 `json:"distance_km"`
+	TimeZone   string  `json:"timezone,omitempty"`
+}
+
+// TideExtreme is a high or low water; Type is "high" or "low"
+type TideExtreme struct {
+	Time   string  `json:"time"`
+	Height float64 `json:"height"`
+	Type   string  `json:"type"`
+}
+
+type TideHeight struct {
+	Time   string  `json:"time"`
+	Height float64 `json:"height"`
+}
+
+// TideResult heights are in metres relative to the station datum
+type TideResult struct {
+	Station  TideStation   `json:"station"`
+	Date     string        `json:"date"`
+	Extremes []TideExtreme `json:"extremes"`
+	Hourly   []TideHeight  `json:"hourly"`
+}
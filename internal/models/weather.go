@@ -1,6 +1,7 @@
 package models
 
 type CurrentWeatherResult struct {
+	Time                string  `json:"time,omitempty"`
 	Temperature         float64 `json:"temperature"`
 	WeatherCode         int     `json:"weather_code"`
 	WindSpeed           float64 `json:"wind_speed"`
@@ -14,6 +15,8 @@ type CurrentWeatherResult struct {
 	NitrogenDioxide     float64 `json:"nitrogen_dioxide"`
 	SulfurDioxide       float64 `json:"sulfur_dioxide"`
 	AQI                 float64 `json:"aqi"`
+	AQIStandard         string  `json:"aqi_standard,omitempty"`
+	AQICategory         string  `json:"aqi_category,omitempty"`
 	Visibility          float64 `json:"visibility"`
 }
 
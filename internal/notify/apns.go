@@ -0,0 +1,143 @@
+package notify
+
+import (
+	"Zephyr/internal/models"
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// APNs rejects provider tokens older than an hour and throttles refreshes
+// more frequent than every 20 minutes
+const apnsTokenLifetime = 40 * time.Minute
+
+// APNs sends notifications through the Apple Push Notification service
+// HTTP/2 API using token based authentication
+type APNs struct {
+	endpoint string
+	keyID    string
+	teamID   string
+	topic    string
+	key      *ecdsa.PrivateKey
+	client   *http.Client
+
+	mu       sync.Mutex
+	token    string
+	issuedAt time.Time
+}
+
+// NewAPNs parses the .p8 signing key. The endpoint is the production or
+// sandbox host, e.g. https://api.sandbox.push.apple.com
+func NewAPNs(endpoint, keyID, teamID, topic, privateKeyPem string) (*APNs, error) {
+	key, err := jwt.ParseECPrivateKeyFromPEM([]byte(privateKeyPem))
+	if err != nil {
+		return nil, err
+	}
+
+	// APNs only speaks HTTP/2
+	transport := &http.Transport{ForceAttemptHTTP2: true}
+
+	return &APNs{
+		endpoint: strings.TrimRight(endpoint, "/"),
+		keyID:    keyID,
+		teamID:   teamID,
+		topic:    topic,
+		key:      key,
+		client:   &http.Client{Transport: transport, Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (a *APNs) Platform() string {
+	return models.PlatformAPNs
+}
+
+func (a *APNs) Send(ctx context.Context, token string, notification Notification) error {
+	providerToken, err := a.providerToken()
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"aps": map[string]interface{}{
+			"alert": map[string]string{
+				"title": notification.Title,
+				"body":  notification.Body,
+			},
+			"sound": "default",
+		},
+	}
+	// Custom data sits next to the aps dictionary
+	for key, value := range notification.Data {
+		payload[key] = value
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.endpoint+"/3/device/"+url.PathEscape(token), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "bearer "+providerToken)
+	req.Header.Set("apns-topic", a.topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", "10")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var apnsError struct {
+		Reason string `json:"reason"`
+	}
+	respBody, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(respBody, &apnsError)
+
+	if resp.StatusCode == http.StatusGone || apnsError.Reason == "BadDeviceToken" || apnsError.Reason == "Unregistered" {
+		return ErrInvalidToken
+	}
+	return fmt.Errorf("apns returned status %d: %s", resp.StatusCode, apnsError.Reason)
+}
+
+// providerToken returns the cached ES256 provider token, signing a new one
+// when it gets old
+func (a *APNs) providerToken() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != "" && time.Since(a.issuedAt) < apnsTokenLifetime {
+		return a.token, nil
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": a.teamID,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = a.keyID
+	signed, err := token.SignedString(a.key)
+	if err != nil {
+		return "", err
+	}
+
+	a.token = signed
+	a.issuedAt = now
+	return a.token, nil
+}
@@ -0,0 +1,86 @@
+package notify
+
+import (
+	"Zephyr/internal/config"
+	"Zephyr/internal/models"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Set of all registered devices as "<platform>:<token>"
+const deviceIndexKey = "devices"
+
+// APNs device tokens are hex encoded, 32 bytes today but documented as
+// variable length
+var apnsTokenPattern = regexp.MustCompile(`^[0-9a-fA-F]{64,200}$`)
+
+// ValidToken reports whether a token has the shape its platform issues
+func ValidToken(platform, token string) bool {
+	switch platform {
+	case models.PlatformAPNs:
+		return apnsTokenPattern.MatchString(token)
+	case models.PlatformFCM:
+		return token != "" && len(token) <= 4096 && !strings.ContainsFunc(token, func(r rune) bool {
+			return r <= ' ' || r == 0x7f
+		})
+	}
+	return false
+}
+
+func deviceKey(platform, token string) string {
+	return fmt.Sprintf("device:%s:%s", platform, token)
+}
+
+// Register stores a device, replacing any earlier registration of its token
+func Register(device models.Device) (models.Device, error) {
+	device.UpdatedAt = time.Now().UTC()
+
+	data, err := json.Marshal(device)
+	if err != nil {
+		return models.Device{}, err
+	}
+
+	pipe := config.RedisClient.TxPipeline()
+	pipe.Set(config.Ctx, deviceKey(device.Platform, device.Token), data, 0)
+	pipe.SAdd(config.Ctx, deviceIndexKey, device.Platform+":"+device.Token)
+	if _, err := pipe.Exec(config.Ctx); err != nil {
+		return models.Device{}, err
+	}
+	return device, nil
+}
+
+// Unregister removes a device and reports whether it was registered
+func Unregister(platform, token string) (bool, error) {
+	pipe := config.RedisClient.TxPipeline()
+	delCmd := pipe.Del(config.Ctx, deviceKey(platform, token))
+	pipe.SRem(config.Ctx, deviceIndexKey, platform+":"+token)
+	if _, err := pipe.Exec(config.Ctx); err != nil {
+		return false, err
+	}
+	return delCmd.Val() > 0, nil
+}
+
+// Devices returns every registered device, skipping entries that fail to load
+func Devices() ([]models.Device, error) {
+	members, err := config.RedisClient.SMembers(config.Ctx, deviceIndexKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	deviceList := make([]models.Device, 0, len(members))
+	for _, member := range members {
+		data, err := config.RedisClient.Get(config.Ctx, "device:"+member).Bytes()
+		if err != nil {
+			continue
+		}
+		var device models.Device
+		if err := json.Unmarshal(data, &device); err != nil {
+			continue
+		}
+		deviceList = append(deviceList, device)
+	}
+	return deviceList, nil
+}
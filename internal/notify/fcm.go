@@ -0,0 +1,177 @@
+package notify
+
+import (
+	"Zephyr/internal/models"
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+// fcmCredentials is the subset of a Google service account key file we need
+type fcmCredentials struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// FCM sends notifications through the Firebase Cloud Messaging HTTP v1 API
+type FCM struct {
+	endpoint    string
+	credentials fcmCredentials
+	key         *rsa.PrivateKey
+	client      *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewFCM loads a service account key file. The endpoint defaults to the
+// public FCM API; the token URI from the key file is used for OAuth.
+func NewFCM(endpoint, credentialsFile string) (*FCM, error) {
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var credentials fcmCredentials
+	if err := json.Unmarshal(data, &credentials); err != nil {
+		return nil, err
+	}
+	if credentials.ProjectID == "" || credentials.ClientEmail == "" || credentials.TokenURI == "" {
+		return nil, errors.New("FCM credentials require project_id, client_email and token_uri")
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(credentials.PrivateKey))
+	if err != nil {
+		return nil, err
+	}
+
+	return &FCM{
+		endpoint:    strings.TrimRight(endpoint, "/"),
+		credentials: credentials,
+		key:         key,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (f *FCM) Platform() string {
+	return models.PlatformFCM
+}
+
+func (f *FCM) Send(ctx context.Context, token string, notification Notification) error {
+	accessToken, err := f.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	message := map[string]interface{}{
+		"message": map[string]interface{}{
+			"token": token,
+			"notification": map[string]string{
+				"title": notification.Title,
+				"body":  notification.Body,
+			},
+			"data":    notification.Data,
+			"android": map[string]string{"priority": "high"},
+		},
+	}
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	apiURL := fmt.Sprintf("%s/v1/projects/%s/messages:send", f.endpoint, f.credentials.ProjectID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(resp.Body)
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return nil
+	case resp.StatusCode == http.StatusNotFound, bytes.Contains(respBody, []byte("UNREGISTERED")):
+		return ErrInvalidToken
+	default:
+		return fmt.Errorf("fcm returned status %d", resp.StatusCode)
+	}
+}
+
+// token returns a cached OAuth access token, exchanging a signed service
+// account assertion for a new one shortly before the old one expires
+func (f *FCM) token(ctx context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.accessToken != "" && time.Now().Before(f.expiresAt) {
+		return f.accessToken, nil
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   f.credentials.ClientEmail,
+		"scope": fcmScope,
+		"aud":   f.credentials.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(f.key)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.credentials.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fcm token exchange returned status %d", resp.StatusCode)
+	}
+
+	var tokenResponse struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResponse); err != nil {
+		return "", err
+	}
+
+	// Refresh a minute early to avoid racing the expiry
+	f.accessToken = tokenResponse.AccessToken
+	f.expiresAt = now.Add(time.Duration(tokenResponse.ExpiresIn)*time.Second - time.Minute)
+	return f.accessToken, nil
+}
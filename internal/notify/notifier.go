@@ -0,0 +1,104 @@
+package notify
+
+import (
+	"Zephyr/internal/config"
+	"Zephyr/internal/logging"
+	"Zephyr/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrInvalidToken is returned when the push service reports that a device
+// token is no longer valid, e.g. because the app was uninstalled
+var ErrInvalidToken = errors.New("device token is no longer valid")
+
+// Notification is a platform independent push message
+type Notification struct {
+	Title string
+	Body  string
+	Data  map[string]string
+}
+
+// Notifier delivers notifications through one push platform
+type Notifier interface {
+	Platform() string
+	Send(ctx context.Context, token string, notification Notification) error
+}
+
+func sentKey(device models.Device, key string) string {
+	return fmt.Sprintf("push:sent:%s:%s:%s", device.Platform, device.Token, key)
+}
+
+// Dispatcher routes notifications to the notifier of a device's platform and
+// makes sure a notification with the same key is only pushed once
+type Dispatcher struct {
+	notifiers map[string]Notifier
+}
+
+func NewDispatcher(notifiers ...Notifier) *Dispatcher {
+	dispatcher := &Dispatcher{notifiers: make(map[string]Notifier)}
+	for _, notifier := range notifiers {
+		dispatcher.notifiers[notifier.Platform()] = notifier
+	}
+	return dispatcher
+}
+
+// Supports reports whether a notifier is configured for the platform
+func (d *Dispatcher) Supports(platform string) bool {
+	_, ok := d.notifiers[platform]
+	return ok
+}
+
+// Send pushes the notification unless one with the same key was delivered
+// to the device within ttl. Devices with invalid tokens are unregistered.
+func (d *Dispatcher) Send(ctx context.Context, device models.Device, key string, ttl time.Duration, notification Notification) error {
+	notifier, ok := d.notifiers[device.Platform]
+	if !ok {
+		return fmt.Errorf("no notifier configured for platform %s", device.Platform)
+	}
+
+	// Claim the key first so concurrent pollers cannot push twice
+	claimed, err := config.RedisClient.SetNX(ctx, sentKey(device, key), 1, ttl).Result()
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return nil
+	}
+
+	if err := notifier.Send(ctx, device.Token, notification); err != nil {
+		// Release the key so the notification is retried on the next poll
+		config.RedisClient.Del(ctx, sentKey(device, key))
+		if errors.Is(err, ErrInvalidToken) {
+			Unregister(device.Platform, device.Token)
+		}
+		return err
+	}
+	return nil
+}
+
+// NewDispatcherFromConfig enables every push platform that is configured
+func NewDispatcherFromConfig() *Dispatcher {
+	var notifiers []Notifier
+	if config.FcmCredentialsFile != "" {
+		fcm, err := NewFCM(config.FcmEndpoint, config.FcmCredentialsFile)
+		if err != nil {
+			logging.L().Error("Failed to configure FCM", zap.Error(err))
+		} else {
+			notifiers = append(notifiers, fcm)
+		}
+	}
+	if config.ApnsPrivateKey != "" {
+		apns, err := NewAPNs(config.ApnsEndpoint, config.ApnsKeyID, config.ApnsTeamID, config.ApnsTopic, config.ApnsPrivateKey)
+		if err != nil {
+			logging.L().Error("Failed to configure APNs", zap.Error(err))
+		} else {
+			notifiers = append(notifiers, apns)
+		}
+	}
+	return NewDispatcher(notifiers...)
+}
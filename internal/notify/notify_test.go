@@ -0,0 +1,307 @@
+package notify
+
+import (
+	"Zephyr/internal/config"
+	"Zephyr/internal/models"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+const apnsTestToken = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+// useTestRedis points the shared client at an in-memory Redis for the test
+func useTestRedis(t *testing.T) *miniredis.Miniredis {
+	t.Helper()
+	server := miniredis.RunT(t)
+	previous := config.RedisClient
+	config.RedisClient = redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() {
+		config.RedisClient.Close()
+		config.RedisClient = previous
+	})
+	return server
+}
+
+// newTestFCM writes a service account key whose token URI points at the fake
+func newTestFCM(t *testing.T, serverURL string) *FCM {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	credentials, _ := json.Marshal(fcmCredentials{
+		ProjectID:   "zeus-test",
+		ClientEmail: "push@zeus-test.iam.gserviceaccount.com",
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		TokenURI:    serverURL + "/token",
+	})
+	path := filepath.Join(t.TempDir(), "fcm.json")
+	if err := os.WriteFile(path, credentials, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	fcm, err := NewFCM(serverURL, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fcm
+}
+
+func newTestAPNs(t *testing.T, serverURL string) *APNs {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	apns, err := NewAPNs(serverURL, "KEY123", "TEAM123", "space.claret.zeus",
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return apns
+}
+
+func TestFCMSend(t *testing.T) {
+	var exchanges atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			exchanges.Add(1)
+			if err := r.ParseForm(); err != nil || r.Form.Get("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" || r.Form.Get("assertion") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `{"access_token":"ya29.test","expires_in":3600}`)
+		case "/v1/projects/zeus-test/messages:send":
+			if r.Header.Get("Authorization") != "Bearer ya29.test" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			var body struct {
+				Message struct {
+					Token string `json:"token"`
+				} `json:"message"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			switch body.Message.Token {
+			case "gone":
+				w.WriteHeader(http.StatusNotFound)
+			case "unregistered":
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error":{"details":[{"errorCode":"UNREGISTERED"}]}}`)
+			case "busy":
+				w.WriteHeader(http.StatusServiceUnavailable)
+			default:
+				fmt.Fprint(w, `{"name":"projects/zeus-test/messages/1"}`)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	fcm := newTestFCM(t, server.URL)
+
+	cases := []struct {
+		token   string
+		invalid bool
+		ok      bool
+	}{
+		{"valid", false, true},
+		{"gone", true, false},
+		{"unregistered", true, false},
+		{"busy", false, false},
+	}
+	for _, c := range cases {
+		err := fcm.Send(context.Background(), c.token, Notification{Title: "Rain soon"})
+		if (err == nil) != c.ok || errors.Is(err, ErrInvalidToken) != c.invalid {
+			t.Errorf("Send(%q) error = %v, want ok %v invalid %v", c.token, err, c.ok, c.invalid)
+		}
+	}
+	if got := exchanges.Load(); got != 1 {
+		t.Errorf("access token exchanged %d times, want once", got)
+	}
+}
+
+func TestAPNsSend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "bearer ") || r.Header.Get("apns-topic") != "space.claret.zeus" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch strings.TrimPrefix(r.URL.Path, "/3/device/") {
+		case apnsTestToken:
+			w.WriteHeader(http.StatusOK)
+		case strings.Repeat("a", 64):
+			w.WriteHeader(http.StatusGone)
+			fmt.Fprint(w, `{"reason":"Unregistered"}`)
+		case strings.Repeat("b", 64):
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"reason":"BadDeviceToken"}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"reason":"BadPath"}`)
+		}
+	}))
+	defer server.Close()
+	apns := newTestAPNs(t, server.URL)
+
+	cases := []struct {
+		token   string
+		invalid bool
+		ok      bool
+	}{
+		{apnsTestToken, false, true},
+		{strings.Repeat("a", 64), true, false},
+		{strings.Repeat("b", 64), true, false},
+		// Escaped, so it cannot address another path
+		{"../../admin", false, false},
+	}
+	for _, c := range cases {
+		err := apns.Send(context.Background(), c.token, Notification{Title: "Storm warning"})
+		if (err == nil) != c.ok || errors.Is(err, ErrInvalidToken) != c.invalid {
+			t.Errorf("Send(%q) error = %v, want ok %v invalid %v", c.token, err, c.ok, c.invalid)
+		}
+	}
+}
+
+func TestValidToken(t *testing.T) {
+	cases := []struct {
+		platform string
+		token    string
+		want     bool
+	}{
+		{models.PlatformAPNs, apnsTestToken, true},
+		{models.PlatformAPNs, "../3/device/" + apnsTestToken, false},
+		{models.PlatformAPNs, "0123", false},
+		{models.PlatformFCM, "fcm-token:APA91bH_example", true},
+		{models.PlatformFCM, "has space", false},
+		{models.PlatformFCM, "", false},
+		{"sms", "12345", false},
+	}
+	for _, c := range cases {
+		if got := ValidToken(c.platform, c.token); got != c.want {
+			t.Errorf("ValidToken(%s, %q) = %v, want %v", c.platform, c.token, got, c.want)
+		}
+	}
+}
+
+// fakeNotifier records sends and fails with err when set
+type fakeNotifier struct {
+	sends atomic.Int32
+	err   error
+}
+
+func (f *fakeNotifier) Platform() string {
+	return models.PlatformFCM
+}
+
+func (f *fakeNotifier) Send(ctx context.Context, token string, notification Notification) error {
+	f.sends.Add(1)
+	return f.err
+}
+
+func TestDispatcherDedupe(t *testing.T) {
+	useTestRedis(t)
+	ctx := context.Background()
+	device := models.Device{Token: "device-1", Platform: models.PlatformFCM}
+	notifier := &fakeNotifier{}
+	dispatcher := NewDispatcher(notifier)
+
+	for range 3 {
+		if err := dispatcher.Send(ctx, device, "alert:qweather:w1:active", time.Hour, Notification{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := notifier.sends.Load(); got != 1 {
+		t.Fatalf("pushed %d times, want once", got)
+	}
+
+	// A different key, such as a status change, is pushed again
+	if err := dispatcher.Send(ctx, device, "alert:qweather:w1:update", time.Hour, Notification{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := notifier.sends.Load(); got != 2 {
+		t.Fatalf("pushed %d times, want 2", got)
+	}
+}
+
+func TestDispatcherReleasesKeyOnFailure(t *testing.T) {
+	redisServer := useTestRedis(t)
+	ctx := context.Background()
+	device := models.Device{Token: "device-1", Platform: models.PlatformFCM}
+	notifier := &fakeNotifier{err: errors.New("fcm returned status 503")}
+	dispatcher := NewDispatcher(notifier)
+
+	if err := dispatcher.Send(ctx, device, "rain", time.Hour, Notification{}); err == nil {
+		t.Fatal("expected the send to fail")
+	}
+	if redisServer.Exists(sentKey(device, "rain")) {
+		t.Fatal("dedupe key kept after a failed send")
+	}
+
+	notifier.err = nil
+	if err := dispatcher.Send(ctx, device, "rain", time.Hour, Notification{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := notifier.sends.Load(); got != 2 {
+		t.Fatalf("pushed %d times, want the failed notification retried", got)
+	}
+}
+
+func TestDispatcherUnregistersInvalidTokens(t *testing.T) {
+	useTestRedis(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			fmt.Fprint(w, `{"access_token":"ya29.test","expires_in":3600}`)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":{"status":"NOT_FOUND","details":[{"errorCode":"UNREGISTERED"}]}}`)
+	}))
+	defer server.Close()
+
+	device, err := Register(models.Device{Token: "uninstalled", Platform: models.PlatformFCM, Latitude: 39.9, Longitude: 116.4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dispatcher := NewDispatcher(newTestFCM(t, server.URL))
+	if err := dispatcher.Send(context.Background(), device, "rain", time.Hour, Notification{}); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("Send error = %v, want ErrInvalidToken", err)
+	}
+
+	devices, err := Devices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(devices) != 0 {
+		t.Fatalf("device with an invalid token is still registered: %v", devices)
+	}
+}
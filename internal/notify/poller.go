@@ -0,0 +1,192 @@
+package notify
+
+import (
+	"Zephyr/internal/alerts"
+	"Zephyr/internal/config"
+	"Zephyr/internal/logging"
+	"Zephyr/internal/models"
+	"Zephyr/internal/providers/openmeteo"
+	"Zephyr/pkg/utils"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// Alerts are pushed once per status change for as long as they last
+	alertDedupeTTL = 72 * time.Hour
+	// At most one rain notification per device within this window
+	rainDedupeTTL = 2 * time.Hour
+	// Precipitation in mm per 15 minutes treated as rain
+	rainThreshold = 0.1
+	// Maximum notification body length in characters
+	maxBodyLength = 180
+)
+
+// Poller pushes alert and "rain soon" notifications to registered devices
+type Poller struct {
+	Interval   time.Duration
+	Sources    func(source string) []alerts.Source
+	Dispatcher *Dispatcher
+	// Pushes sent concurrently
+	Workers int
+}
+
+func NewPoller(sources func(source string) []alerts.Source, dispatcher *Dispatcher) *Poller {
+	return &Poller{
+		Interval:   config.AlertPollInterval,
+		Sources:    sources,
+		Dispatcher: dispatcher,
+		Workers:    config.PushWorkers,
+	}
+}
+
+// Run polls until the context is cancelled
+func (p *Poller) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+
+	for {
+		p.Poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll fetches alerts and nowcasts once per distinct location and language
+func (p *Poller) Poll(ctx context.Context) {
+	deviceList, err := Devices()
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to list push devices", zap.Error(err))
+		return
+	}
+
+	// Devices within ~1.11 km sharing language and source share one fetch
+	groups := make(map[string][]models.Device)
+	for _, device := range deviceList {
+		if !p.Dispatcher.Supports(device.Platform) {
+			continue
+		}
+		key := fmt.Sprintf("%.2f:%.2f:%s:%s", device.Latitude, device.Longitude, device.Language, device.Source)
+		groups[key] = append(groups[key], device)
+	}
+
+	var jobs []func()
+	for _, group := range groups {
+		first := group[0]
+		latitude := fmt.Sprintf("%.2f", first.Latitude)
+		longitude := fmt.Sprintf("%.2f", first.Longitude)
+
+		var notifications []keyedNotification
+		if alertList, _, err := alerts.Collect(ctx, p.Sources(first.Source), first.Latitude, first.Longitude, first.Language); err != nil {
+			logging.FromContext(ctx).Warn("Failed to fetch alerts for devices", zap.String("latitude", latitude), zap.String("longitude", longitude), zap.Error(err))
+		} else {
+			notifications = append(notifications, alertNotifications(alertList)...)
+		}
+
+		if nowcast, err := openmeteo.GetNowcast(ctx, latitude, longitude); err != nil {
+			logging.FromContext(ctx).Warn("Failed to fetch nowcast for devices", zap.String("latitude", latitude), zap.String("longitude", longitude), zap.Error(err))
+		} else if minutes, ok := rainStartsIn(nowcast, time.Now()); ok {
+			notifications = append(notifications, rainNotification(first.Language, minutes))
+		}
+
+		for _, device := range group {
+			for _, notification := range notifications {
+				jobs = append(jobs, func() {
+					err := p.Dispatcher.Send(ctx, device, notification.key, notification.ttl, notification.Notification)
+					if err != nil {
+						logging.FromContext(ctx).Warn("Failed to push to device", zap.String("platform", device.Platform), zap.Error(err))
+					}
+				})
+			}
+		}
+	}
+	// Bounded so thousands of devices do not open thousands of connections
+	utils.RunBatch(jobs, p.Workers)
+}
+
+type keyedNotification struct {
+	Notification
+	key string
+	ttl time.Duration
+}
+
+// alertNotifications builds one notification per active alert. The status is
+// part of the key so updates are pushed again while repeats are not.
+func alertNotifications(alertList []models.Alert) []keyedNotification {
+	var notifications []keyedNotification
+	for _, alert := range alertList {
+		if alert.Status == "cancel" {
+			continue
+		}
+		title := alert.Headline
+		if title == "" {
+			title = alert.Event
+		}
+		notifications = append(notifications, keyedNotification{
+			Notification: Notification{
+				Title: title,
+				Body:  truncate(alert.Description, maxBodyLength),
+				Data: map[string]string{
+					"type":     "alert",
+					"source":   alert.Source,
+					"alert_id": alert.ID,
+					"severity": string(alert.Severity),
+				},
+			},
+			key: fmt.Sprintf("alert:%s:%s:%s", alert.Source, alert.ID, alert.Status),
+			ttl: alertDedupeTTL,
+		})
+	}
+	return notifications
+}
+
+// rainStartsIn reports the minutes until rain starts within the next hour.
+// Nothing is reported when it is already raining.
+func rainStartsIn(nowcast models.NowcastResult, now time.Time) (int, bool) {
+	first := true
+	for _, slot := range nowcast.Minutely {
+		start, err := time.Parse("2006-01-02T15:04", slot.Time)
+		if err != nil || start.Add(15*time.Minute).Before(now) {
+			continue
+		}
+		if start.Sub(now) > time.Hour {
+			break
+		}
+		if slot.Precipitation >= rainThreshold {
+			if first {
+				return 0, false
+			}
+			return int(start.Sub(now).Minutes()), true
+		}
+		first = false
+	}
+	return 0, false
+}
+
+func rainNotification(language string, minutes int) keyedNotification {
+	notification := Notification{
+		Title: "Rain soon",
+		Body:  fmt.Sprintf("Rain expected in about %d minutes", minutes),
+		Data:  map[string]string{"type": "rain"},
+	}
+	if strings.HasPrefix(language, "zh") {
+		notification.Title = "即将降雨"
+		notification.Body = fmt.Sprintf("预计约 %d 分钟后开始降雨", minutes)
+	}
+	return keyedNotification{Notification: notification, key: "rain", ttl: rainDedupeTTL}
+}
+
+func truncate(text string, length int) string {
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+	return string(runes[:length-1]) + "…"
+}
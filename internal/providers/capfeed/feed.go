@@ -0,0 +1,412 @@
+package capfeed
+
+import (
+	"Zephyr/internal/alerts"
+	"Zephyr/internal/cache"
+	"Zephyr/internal/logging"
+	"Zephyr/internal/models"
+	"Zephyr/internal/upstream"
+	"Zephyr/pkg/utils"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Alert feeds change quickly, so they are cached for less than forecasts
+const feedCacheTTL = 5 * time.Minute
+
+// capAlert is a Common Alerting Protocol 1.2 <alert> document
+type capAlert struct {
+	Identifier string    `xml:"identifier"`
+	Sender     string    `xml:"sender"`
+	Sent       string    `xml:"sent"`
+	Status     string    `xml:"status"`
+	MsgType    string    `xml:"msgType"`
+	Info       []capInfo `xml:"info"`
+}
+
+type capInfo struct {
+	Language    string `xml:"language"`
+	Event       string `xml:"event"`
+	Urgency     string `xml:"urgency"`
+	Severity    string `xml:"severity"`
+	Certainty   string `xml:"certainty"`
+	Effective   string `xml:"effective"`
+	Onset       string `xml:"onset"`
+	Expires     string `xml:"expires"`
+	SenderName  string `xml:"senderName"`
+	Headline    string `xml:"headline"`
+	Description string `xml:"description"`
+	Parameter   []struct {
+		ValueName string `xml:"valueName"`
+		Value     string `xml:"value"`
+	} `xml:"parameter"`
+	Area []capArea `xml:"area"`
+}
+
+type capArea struct {
+	AreaDesc string   `xml:"areaDesc"`
+	Polygon  []string `xml:"polygon"`
+	Circle   []string `xml:"circle"`
+}
+
+// atomFeed is an ATOM index of CAP alerts, either embedded in <content>
+// or linked from each entry
+type atomFeed struct {
+	Entries []struct {
+		Links []struct {
+			Href string `xml:"href,attr"`
+		} `xml:"link"`
+		Content struct {
+			Alerts []capAlert `xml:"alert"`
+		} `xml:"content"`
+	} `xml:"entry"`
+}
+
+// feedAlert is a parsed alert with the areas needed for location matching
+type feedAlert struct {
+	Alert models.Alert `json:"alert"`
+	Areas []capArea    `json:"areas"`
+}
+
+// FeedSource reads alerts from a CAP 1.2 document or an ATOM feed of CAP
+// alerts. Location may be an http(s) URL or a local file path.
+type FeedSource struct {
+	Location string
+}
+
+func NewFeedSource(location string) FeedSource {
+	return FeedSource{Location: location}
+}
+
+func (s FeedSource) Name() string {
+	return "cap"
+}
+
+// Fetch returns the feed's alerts whose area covers the point
+func (s FeedSource) Fetch(ctx context.Context, latitude, longitude float64, language string) ([]models.Alert, error) {
+	feedAlerts, err := s.loadFeed(ctx, language)
+	if err != nil {
+		return nil, err
+	}
+
+	var alerts []models.Alert
+	for _, feedAlert := range feedAlerts {
+		if coversPoint(feedAlert.Areas, latitude, longitude) {
+			alerts = append(alerts, feedAlert.Alert)
+		}
+	}
+	return alerts, nil
+}
+
+// loadFeed parses the whole feed, caching it independently of the location
+func (s FeedSource) loadFeed(ctx context.Context, language string) ([]feedAlert, error) {
+	cacheKey := fmt.Sprintf("cap:feed:%s:%s", s.Location, language)
+	var cached []feedAlert
+	if cache.Get(ctx, "capfeed", cacheKey, &cached) {
+		return cached, nil
+	}
+
+	body, err := readDocument(ctx, "{feed}", s.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	capAlerts, err := s.parseDocument(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+
+	feedAlerts := s.activeAlerts(capAlerts, language, time.Now())
+	cache.Set(ctx, "capfeed", cacheKey, feedAlerts, feedCacheTTL)
+	return feedAlerts, nil
+}
+
+// activeAlerts picks the info block for the language of each alert and
+// drops alerts without info or already expired at now
+func (s FeedSource) activeAlerts(capAlerts []capAlert, language string, now time.Time) []feedAlert {
+	feedAlerts := make([]feedAlert, 0, len(capAlerts))
+	for _, capAlert := range capAlerts {
+		info, ok := selectInfo(capAlert.Info, language)
+		if expires := alerts.ParseTime(info.Expires); !ok || (expires != nil && expires.Before(now)) {
+			continue
+		}
+		feedAlerts = append(feedAlerts, feedAlert{
+			Alert: toAlert(capAlert, info, s.Name()),
+			Areas: info.Area,
+		})
+	}
+	return feedAlerts
+}
+
+// parseDocument accepts a single CAP alert or an ATOM feed; linked
+// entries are resolved relative to the feed location
+func (s FeedSource) parseDocument(ctx context.Context, body []byte) ([]capAlert, error) {
+	root, err := rootElement(body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch root {
+	case "alert":
+		var alert capAlert
+		if err := xml.Unmarshal(body, &alert); err != nil {
+			return nil, fmt.Errorf("failed to parse CAP alert: %w", err)
+		}
+		return []capAlert{alert}, nil
+	case "feed":
+		var feed atomFeed
+		if err := xml.Unmarshal(body, &feed); err != nil {
+			return nil, fmt.Errorf("failed to parse ATOM feed: %w", err)
+		}
+
+		var alerts []capAlert
+		for _, entry := range feed.Entries {
+			if len(entry.Content.Alerts) > 0 {
+				alerts = append(alerts, entry.Content.Alerts...)
+				continue
+			}
+			href := ""
+			for _, link := range entry.Links {
+				if link.Href != "" {
+					href = link.Href
+					break
+				}
+			}
+			if href == "" {
+				continue
+			}
+			location, err := resolveLocation(s.Location, href)
+			if err != nil {
+				logging.FromContext(ctx).Warn("Skipping linked CAP alert", zap.String("provider", "capfeed"), zap.String("href", href), zap.Error(err))
+				continue
+			}
+			linked, err := readDocument(ctx, "{alert}", location)
+			if err != nil {
+				logging.FromContext(ctx).Warn("Failed to read linked CAP alert", zap.String("provider", "capfeed"), zap.String("href", href), zap.Error(err))
+				continue
+			}
+			var alert capAlert
+			if err := xml.Unmarshal(linked, &alert); err != nil {
+				logging.FromContext(ctx).Warn("Failed to parse linked CAP alert", zap.String("provider", "capfeed"), zap.String("href", href), zap.Error(err))
+				continue
+			}
+			alerts = append(alerts, alert)
+		}
+		return alerts, nil
+	default:
+		return nil, fmt.Errorf("unsupported alert document root <%s>", root)
+	}
+}
+
+// isRemote reports whether a location is an http(s) URL
+func isRemote(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// readDocument loads a feed or alert from a URL or the filesystem. Feed URLs
+// are arbitrary, so requests are labelled with a fixed endpoint.
+func readDocument(ctx context.Context, endpoint, location string) ([]byte, error) {
+	if isRemote(location) {
+		resp, err := upstream.Get(ctx, "capfeed", endpoint, location)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("alert feed %s returned status %d", location, resp.StatusCode)
+		}
+		return io.ReadAll(resp.Body)
+	}
+	return os.ReadFile(strings.TrimPrefix(location, "file://"))
+}
+
+// resolveLocation resolves an entry link against the feed location. Links
+// of a remote feed must stay remote so a feed cannot make us read local
+// files; only a feed configured as a local path may link to the filesystem.
+func resolveLocation(base, ref string) (string, error) {
+	if isRemote(base) {
+		baseURL, err := url.Parse(base)
+		if err != nil {
+			return "", err
+		}
+		refURL, err := url.Parse(ref)
+		if err != nil {
+			return "", err
+		}
+		resolved := baseURL.ResolveReference(refURL).String()
+		if !isRemote(resolved) {
+			return "", fmt.Errorf("remote feed links to non-http location %q", ref)
+		}
+		return resolved, nil
+	}
+	if strings.Contains(ref, "://") || filepath.IsAbs(ref) {
+		return ref, nil
+	}
+	return filepath.Join(filepath.Dir(strings.TrimPrefix(base, "file://")), ref), nil
+}
+
+// rootElement returns the local name of the first XML element
+func rootElement(body []byte) (string, error) {
+	decoder := xml.NewDecoder(strings.NewReader(string(body)))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", fmt.Errorf("failed to read alert document: %w", err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+// selectInfo picks the info block matching the language, falling back to the first
+func selectInfo(infos []capInfo, language string) (capInfo, bool) {
+	if len(infos) == 0 {
+		return capInfo{}, false
+	}
+	language = strings.ToLower(language)
+	if language != "" {
+		for _, info := range infos {
+			if strings.HasPrefix(strings.ToLower(info.Language), language) {
+				return info, true
+			}
+		}
+	}
+	return infos[0], true
+}
+
+func toAlert(alert capAlert, info capInfo, source string) models.Alert {
+	sender := info.SenderName
+	if sender == "" {
+		sender = alert.Sender
+	}
+	onset := alerts.ParseTime(info.Onset)
+	if onset == nil {
+		onset = alerts.ParseTime(info.Effective)
+	}
+
+	// MeteoAlarm style feeds carry the color as "2; yellow; Moderate",
+	// feeds rendered by Zeus as a plain "color" parameter
+	severity := alerts.ParseSeverity(info.Severity)
+	color := models.ColorUnknown
+	for _, parameter := range info.Parameter {
+		if strings.EqualFold(parameter.ValueName, "awareness_level") || strings.EqualFold(parameter.ValueName, "color") {
+			color = alerts.ParseColor(parameter.Value)
+		}
+	}
+	if color == models.ColorUnknown {
+		color = alerts.ColorForSeverity(severity)
+	}
+
+	var sent time.Time
+	if t := alerts.ParseTime(alert.Sent); t != nil {
+		sent = *t
+	}
+
+	// CAP message types map onto the QWeather style status values
+	status := "active"
+	switch strings.ToLower(alert.MsgType) {
+	case "update":
+		status = "update"
+	case "cancel":
+		status = "cancel"
+	}
+
+	return models.Alert{
+		ID:          alert.Identifier,
+		Source:      source,
+		Sender:      sender,
+		Event:       info.Event,
+		Headline:    info.Headline,
+		Description: info.Description,
+		Language:    info.Language,
+		Severity:    severity,
+		Urgency:     alerts.ParseUrgency(info.Urgency),
+		Certainty:   alerts.ParseCertainty(info.Certainty),
+		Color:       color,
+		Status:      status,
+		Sent:        sent,
+		Onset:       onset,
+		Expires:     alerts.ParseTime(info.Expires),
+		Area:        toArea(info.Area),
+	}
+}
+
+// toArea merges the area descriptions and polygons of an info block
+func toArea(areas []capArea) *models.AlertArea {
+	if len(areas) == 0 {
+		return nil
+	}
+	var descriptions []string
+	area := &models.AlertArea{}
+	for _, a := range areas {
+		if a.AreaDesc != "" {
+			descriptions = append(descriptions, a.AreaDesc)
+		}
+		for _, polygon := range a.Polygon {
+			if points := parsePoints(polygon); len(points) >= 3 {
+				area.Polygons = append(area.Polygons, points)
+			}
+		}
+	}
+	area.Description = strings.Join(descriptions, "; ")
+	return area
+}
+
+// coversPoint reports whether any polygon or circle of the areas contains
+// the point. Areas described only by geocodes cannot be matched.
+func coversPoint(areas []capArea, latitude, longitude float64) bool {
+	for _, area := range areas {
+		for _, polygon := range area.Polygon {
+			if points := parsePoints(polygon); len(points) >= 3 &&
+				utils.PointInPolygon(latitude, longitude, points) {
+				return true
+			}
+		}
+		for _, circle := range area.Circle {
+			// "lat,lon radius" with the radius in kilometers
+			fields := strings.Fields(circle)
+			if len(fields) != 2 {
+				continue
+			}
+			center := parsePoints(fields[0])
+			radius, err := strconv.ParseFloat(fields[1], 64)
+			if len(center) != 1 || err != nil {
+				continue
+			}
+			if utils.DistanceKm(latitude, longitude, center[0][0], center[0][1]) <= radius {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parsePoints parses space separated "lat,lon" pairs
+func parsePoints(value string) [][2]float64 {
+	var points [][2]float64
+	for _, pair := range strings.Fields(value) {
+		parts := strings.Split(pair, ",")
+		if len(parts) != 2 {
+			return nil
+		}
+		lat, err1 := strconv.ParseFloat(parts[0], 64)
+		lon, err2 := strconv.ParseFloat(parts[1], 64)
+		if err1 != nil || err2 != nil {
+			return nil
+		}
+		points = append(points, [2]float64{lat, lon})
+	}
+	return points
+}
@@ -0,0 +1,175 @@
+package capfeed
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+func TestParseDocument(t *testing.T) {
+	cases := []struct {
+		name    string
+		fixture string
+		ids     []string
+	}{
+		{"cap alert", "alert.xml", []string{"urn:oid:2.49.0.1.276.0.DWD.PVW.1700000000000.1"}},
+		{"atom with embedded and linked entries", "feed.xml", []string{"flood-1", "urn:oid:2.49.0.1.276.0.DWD.PVW.1700000000000.1", "expired-frost-1"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			source := NewFeedSource(filepath.Join("testdata", c.fixture))
+			alerts, err := source.parseDocument(context.Background(), readFixture(t, c.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(alerts) != len(c.ids) {
+				t.Fatalf("got %d alerts, want %d", len(alerts), len(c.ids))
+			}
+			for i, id := range c.ids {
+				if alerts[i].Identifier != id {
+					t.Errorf("alert %d identifier = %q, want %q", i, alerts[i].Identifier, id)
+				}
+			}
+		})
+	}
+
+	if _, err := NewFeedSource("rss.xml").parseDocument(context.Background(), []byte("<rss/>")); err == nil {
+		t.Error("expected an error for an unsupported root element")
+	}
+}
+
+func TestRemoteFeedLinksStayRemote(t *testing.T) {
+	fixture, err := filepath.Abs(filepath.Join("testdata", "alert.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := NewFeedSource("https://alerts.example.org/feed.xml")
+
+	cases := []struct {
+		ref  string
+		want string
+	}{
+		{"/alerts/1.xml", "https://alerts.example.org/alerts/1.xml"},
+		{"2.xml", "https://alerts.example.org/2.xml"},
+		{"http://other.example.org/3.xml", "http://other.example.org/3.xml"},
+		{"file://" + fixture, ""},
+		{"FILE://" + fixture, ""},
+	}
+	for _, c := range cases {
+		got, err := resolveLocation(source.Location, c.ref)
+		if c.want == "" {
+			if err == nil {
+				t.Errorf("resolveLocation(%q) = %q, want an error", c.ref, got)
+			}
+			continue
+		}
+		if err != nil || got != c.want {
+			t.Errorf("resolveLocation(%q) = %q, %v, want %q", c.ref, got, err, c.want)
+		}
+	}
+
+	// A readable local CAP alert linked from a remote feed must not be parsed
+	feed := []byte(`<feed xmlns="http://www.w3.org/2005/Atom"><entry><link href="file://` + fixture + `"/></entry></feed>`)
+	alerts, err := source.parseDocument(context.Background(), feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 0 {
+		t.Fatalf("remote feed read %d local documents", len(alerts))
+	}
+}
+
+func TestActiveAlerts(t *testing.T) {
+	source := NewFeedSource(filepath.Join("testdata", "feed.xml"))
+	capAlerts, err := source.parseDocument(context.Background(), readFixture(t, "feed.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2030, 1, 15, 12, 0, 0, 0, time.UTC)
+	feedAlerts := source.activeAlerts(capAlerts, "en", now)
+	if len(feedAlerts) != 2 {
+		t.Fatalf("got %d active alerts, want 2", len(feedAlerts))
+	}
+	for _, feedAlert := range feedAlerts {
+		if feedAlert.Alert.ID == "expired-frost-1" {
+			t.Error("expired alert was kept")
+		}
+	}
+	if status := feedAlerts[0].Alert.Status; status != "update" {
+		t.Errorf("status = %q, want update", status)
+	}
+
+	// Everything has expired a day later
+	if feedAlerts := source.activeAlerts(capAlerts, "en", now.Add(48*time.Hour)); len(feedAlerts) != 0 {
+		t.Errorf("got %d alerts after expiry, want 0", len(feedAlerts))
+	}
+}
+
+func TestSelectInfo(t *testing.T) {
+	source := NewFeedSource(filepath.Join("testdata", "alert.xml"))
+	capAlerts, err := source.parseDocument(context.Background(), readFixture(t, "alert.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	infos := capAlerts[0].Info
+
+	cases := []struct {
+		language string
+		event    string
+	}{
+		{"en", "GALE-FORCE GUSTS"},
+		{"EN-gb", "GALE-FORCE GUSTS"},
+		{"de", "STURMBÖEN"},
+		// Unknown and missing languages fall back to the first block
+		{"fr", "STURMBÖEN"},
+		{"", "STURMBÖEN"},
+	}
+	for _, c := range cases {
+		info, ok := selectInfo(infos, c.language)
+		if !ok || info.Event != c.event {
+			t.Errorf("selectInfo(%q) = %q, %v, want %q", c.language, info.Event, ok, c.event)
+		}
+	}
+
+	if _, ok := selectInfo(nil, "en"); ok {
+		t.Error("selectInfo found an info block in an empty alert")
+	}
+}
+
+func TestCoversPoint(t *testing.T) {
+	berlin := []capArea{{Polygon: []string{"52.3,13.0 52.7,13.0 52.7,13.8 52.3,13.8 52.3,13.0"}}}
+	spree := []capArea{{Circle: []string{"51.76,14.33 15"}}}
+
+	cases := []struct {
+		name      string
+		areas     []capArea
+		latitude  float64
+		longitude float64
+		want      bool
+	}{
+		{"inside polygon", berlin, 52.52, 13.40, true},
+		{"outside polygon", berlin, 48.14, 11.58, false},
+		{"inside circle", spree, 51.80, 14.40, true},
+		{"outside circle", spree, 52.52, 13.40, false},
+		{"polygon with too few points", []capArea{{Polygon: []string{"52.3,13.0 52.7,13.0"}}}, 52.5, 13.0, false},
+		{"malformed circle", []capArea{{Circle: []string{"51.76,14.33"}}}, 51.76, 14.33, false},
+		{"geocode only", []capArea{{AreaDesc: "Berlin"}}, 52.52, 13.40, false},
+	}
+	for _, c := range cases {
+		if got := coversPoint(c.areas, c.latitude, c.longitude); got != c.want {
+			t.Errorf("%s: coversPoint = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package capfeed
+
+import (
+	"Zephyr/internal/models"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Media types served by the alert endpoint
+const (
+	MIMECAP  = "application/cap+xml"
+	MIMEAtom = "application/atom+xml"
+	MIMERSS  = "application/rss+xml"
+)
+
+// CAP requires an explicit offset and forbids the "Z" designator
+const capTimeLayout = "2006-01-02T15:04:05-07:00"
+
+type capAlertOut struct {
+	XMLName    xml.Name   `xml:"urn:oasis:names:tc:emergency:cap:1.2 alert"`
+	Identifier string     `xml:"identifier"`
+	Sender     string     `xml:"sender"`
+	Sent       string     `xml:"sent"`
+	Status     string     `xml:"status"`
+	MsgType    string     `xml:"msgType"`
+	Scope      string     `xml:"scope"`
+	Info       capInfoOut `xml:"info"`
+}
+
+type capInfoOut struct {
+	Language    string            `xml:"language,omitempty"`
+	Category    string            `xml:"category"`
+	Event       string            `xml:"event"`
+	Urgency     string            `xml:"urgency"`
+	Severity    string            `xml:"severity"`
+	Certainty   string            `xml:"certainty"`
+	Onset       string            `xml:"onset,omitempty"`
+	Expires     string            `xml:"expires,omitempty"`
+	SenderName  string            `xml:"senderName,omitempty"`
+	Headline    string            `xml:"headline,omitempty"`
+	Description string            `xml:"description,omitempty"`
+	Parameter   []capParameterOut `xml:"parameter,omitempty"`
+	Area        *capAreaOut       `xml:"area,omitempty"`
+}
+
+type capParameterOut struct {
+	ValueName string `xml:"valueName"`
+	Value     string `xml:"value"`
+}
+
+type capAreaOut struct {
+	AreaDesc string   `xml:"areaDesc"`
+	Polygon  []string `xml:"polygon,omitempty"`
+}
+
+type atomFeedOut struct {
+	XMLName xml.Name       `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string         `xml:"id"`
+	Title   string         `xml:"title"`
+	Updated string         `xml:"updated"`
+	Link    atomLinkOut    `xml:"link"`
+	Entries []atomEntryOut `xml:"entry"`
+}
+
+type atomLinkOut struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntryOut struct {
+	ID      string `xml:"id"`
+	Title   string `xml:"title"`
+	Updated string `xml:"updated"`
+	Summary string `xml:"summary,omitempty"`
+	Author  struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+	Content struct {
+		Type  string      `xml:"type,attr"`
+		Alert capAlertOut `xml:"alert"`
+	} `xml:"content"`
+}
+
+type rssOut struct {
+	XMLName xml.Name `xml:"rss"`
+	Version string   `xml:"version,attr"`
+	Channel struct {
+		Title         string       `xml:"title"`
+		Link          string       `xml:"link"`
+		Description   string       `xml:"description"`
+		LastBuildDate string       `xml:"lastBuildDate"`
+		Items         []rssItemOut `xml:"item"`
+	} `xml:"channel"`
+}
+
+type rssItemOut struct {
+	Title       string `xml:"title"`
+	Description string `xml:"description,omitempty"`
+	GUID        struct {
+		IsPermaLink string `xml:"isPermaLink,attr"`
+		Value       string `xml:",chardata"`
+	} `xml:"guid"`
+	PubDate  string `xml:"pubDate"`
+	Category string `xml:"category"`
+}
+
+// RenderCAP renders a single alert as a CAP 1.2 document
+func RenderCAP(alert models.Alert, updated time.Time) ([]byte, error) {
+	return marshal(toCAPAlert(withDefaults(alert, updated)))
+}
+
+// RenderAtom renders alerts as an ATOM feed with each CAP alert embedded
+// in its entry, the layout used by national CAP feeds
+func RenderAtom(alertList []models.Alert, feedURL string, updated time.Time) ([]byte, error) {
+	feed := atomFeedOut{
+		ID:      feedURL,
+		Title:   "Weather alerts",
+		Updated: updated.Format(time.RFC3339),
+		Link:    atomLinkOut{Rel: "self", Href: feedURL},
+		Entries: make([]atomEntryOut, 0, len(alertList)),
+	}
+	for _, alert := range alertList {
+		alert = withDefaults(alert, updated)
+		entry := atomEntryOut{
+			ID:      "urn:zeus:alert:" + alert.Source + ":" + alert.ID,
+			Title:   alert.Headline,
+			Updated: alert.Sent.Format(time.RFC3339),
+			Summary: alert.Description,
+		}
+		entry.Author.Name = alert.Sender
+		entry.Content.Type = MIMECAP
+		entry.Content.Alert = toCAPAlert(alert)
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return marshal(feed)
+}
+
+// RenderRSS renders alerts as an RSS 2.0 channel
+func RenderRSS(alertList []models.Alert, feedURL string, updated time.Time) ([]byte, error) {
+	feed := rssOut{Version: "2.0"}
+	feed.Channel.Title = "Weather alerts"
+	feed.Channel.Link = feedURL
+	feed.Channel.Description = "Active weather alerts"
+	feed.Channel.LastBuildDate = updated.Format(time.RFC1123Z)
+	for _, alert := range alertList {
+		alert = withDefaults(alert, updated)
+		item := rssItemOut{
+			Title:       alert.Headline,
+			Description: alert.Description,
+			PubDate:     alert.Sent.Format(time.RFC1123Z),
+			Category:    string(alert.Severity),
+		}
+		item.GUID.IsPermaLink = "false"
+		item.GUID.Value = alert.Source + ":" + alert.ID
+		feed.Channel.Items = append(feed.Channel.Items, item)
+	}
+	return marshal(feed)
+}
+
+// withDefaults fills the fields CAP, ATOM and RSS require but some sources
+// leave empty. A missing sent time falls back to the onset, then to the
+// render time; a missing ID is derived from the alert content so it stays
+// the same across renders.
+func withDefaults(alert models.Alert, updated time.Time) models.Alert {
+	if alert.Sent.IsZero() {
+		if alert.Onset != nil {
+			alert.Sent = *alert.Onset
+		} else {
+			alert.Sent = updated
+		}
+	}
+	if alert.ID == "" {
+		alert.ID = syntheticID(alert)
+	}
+	return alert
+}
+
+// syntheticID hashes the fields that identify an alert when its source
+// provides no identifier
+func syntheticID(alert models.Alert) string {
+	hash := sha256.New()
+	for _, field := range []string{alert.Source, alert.Event, alert.Headline, alert.Language} {
+		hash.Write([]byte(field))
+		hash.Write([]byte{0})
+	}
+	for _, t := range []*time.Time{alert.Onset, alert.Expires} {
+		if t != nil {
+			hash.Write([]byte(t.UTC().Format(time.RFC3339)))
+		}
+		hash.Write([]byte{0})
+	}
+	return "generated-" + hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
+func toCAPAlert(alert models.Alert) capAlertOut {
+	// Status values map back onto CAP message types
+	msgType := "Alert"
+	switch alert.Status {
+	case "update":
+		msgType = "Update"
+	case "cancel":
+		msgType = "Cancel"
+	}
+
+	out := capAlertOut{
+		Identifier: alert.ID,
+		// CAP senders may not contain spaces, the display name goes into senderName
+		Sender:  "zeus." + alert.Source,
+		Sent:    alert.Sent.Format(capTimeLayout),
+		Status:  "Actual",
+		MsgType: msgType,
+		Scope:   "Public",
+		Info: capInfoOut{
+			Language:    alert.Language,
+			Category:    "Met",
+			Event:       alert.Event,
+			Urgency:     string(alert.Urgency),
+			Severity:    string(alert.Severity),
+			Certainty:   string(alert.Certainty),
+			SenderName:  alert.Sender,
+			Headline:    alert.Headline,
+			Description: alert.Description,
+		},
+	}
+	if alert.Onset != nil {
+		out.Info.Onset = alert.Onset.Format(capTimeLayout)
+	}
+	if alert.Expires != nil {
+		out.Info.Expires = alert.Expires.Format(capTimeLayout)
+	}
+	if alert.Color != "" && alert.Color != models.ColorUnknown {
+		out.Info.Parameter = append(out.Info.Parameter, capParameterOut{
+			ValueName: "color",
+			Value:     string(alert.Color),
+		})
+	}
+	if alert.Area != nil {
+		// areaDesc is mandatory in CAP
+		area := &capAreaOut{AreaDesc: alert.Area.Description}
+		if area.AreaDesc == "" {
+			area.AreaDesc = "Affected area"
+		}
+		for _, polygon := range alert.Area.Polygons {
+			area.Polygon = append(area.Polygon, formatPolygon(polygon))
+		}
+		out.Info.Area = area
+	}
+	return out
+}
+
+// formatPolygon writes "lat,lon" pairs, closing the ring as CAP requires
+func formatPolygon(points [][2]float64) string {
+	pairs := make([]string, 0, len(points)+1)
+	for _, point := range points {
+		pairs = append(pairs, fmt.Sprintf("%g,%g", point[0], point[1]))
+	}
+	if len(points) > 0 && points[0] != points[len(points)-1] {
+		pairs = append(pairs, pairs[0])
+	}
+	return strings.Join(pairs, " ")
+}
+
+func marshal(v interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
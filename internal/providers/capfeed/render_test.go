@@ -0,0 +1,75 @@
+package capfeed
+
+import (
+	"Zephyr/internal/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithDefaults(t *testing.T) {
+	updated := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	sent := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	onset := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name  string
+		alert models.Alert
+		sent  time.Time
+	}{
+		{"sent kept", models.Alert{ID: "a", Sent: sent, Onset: &onset}, sent},
+		{"onset when sent missing", models.Alert{ID: "a", Onset: &onset}, onset},
+		{"render time when both missing", models.Alert{ID: "a"}, updated},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			alert := withDefaults(c.alert, updated)
+			if !alert.Sent.Equal(c.sent) {
+				t.Errorf("Sent = %v, want %v", alert.Sent, c.sent)
+			}
+			if alert.ID != "a" {
+				t.Errorf("ID = %q, want %q", alert.ID, "a")
+			}
+		})
+	}
+}
+
+func TestSyntheticID(t *testing.T) {
+	alert := models.Alert{Source: "qweather", Event: "Rainstorm", Headline: "Rainstorm warning"}
+	first := withDefaults(alert, time.Now()).ID
+	second := withDefaults(alert, time.Now().Add(time.Hour)).ID
+	if first == "" || first != second {
+		t.Errorf("ID = %q then %q, want a stable non-empty ID", first, second)
+	}
+
+	alert.Headline = "Heavy rainstorm warning"
+	if other := withDefaults(alert, time.Now()).ID; other == first {
+		t.Errorf("ID = %q for a different alert, want a different ID", other)
+	}
+}
+
+func TestRenderRequiredFields(t *testing.T) {
+	updated := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	alertList := []models.Alert{{Source: "qweather", Headline: "Fog"}}
+
+	renders := map[string]func() ([]byte, error){
+		"cap":  func() ([]byte, error) { return RenderCAP(alertList[0], updated) },
+		"atom": func() ([]byte, error) { return RenderAtom(alertList, "https://example.com/alerts", updated) },
+		"rss":  func() ([]byte, error) { return RenderRSS(alertList, "https://example.com/alerts", updated) },
+	}
+	for name, render := range renders {
+		t.Run(name, func(t *testing.T) {
+			body, err := render()
+			if err != nil {
+				t.Fatal(err)
+			}
+			output := string(body)
+			if strings.Contains(output, "0001-01-01") || strings.Contains(output, "Jan 0001") {
+				t.Errorf("output has a zero time:\n%s", output)
+			}
+			if !strings.Contains(output, "generated-") {
+				t.Errorf("output has no synthesized identifier:\n%s", output)
+			}
+		})
+	}
+}
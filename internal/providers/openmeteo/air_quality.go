@@ -0,0 +1,289 @@
+package openmeteo
+
+import (
+	"Zephyr/internal/cache"
+	"Zephyr/internal/config"
+	"Zephyr/internal/models"
+	"Zephyr/internal/upstream"
+	"Zephyr/pkg/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// Open-Meteo reports european_aqi for the raw index
+const omAirQualityScale = "european_aqi"
+
+// omHourlyAirQuality keeps nulls as nil so hourly series stay index aligned
+type omHourlyAirQuality struct {
+	Time            []string   `json:"time"`
+	Pm25            []*float64 `json:"pm2_5"`
+	Pm10            []*float64 `json:"pm10"`
+	Ozone           []*float64 `json:"ozone"`
+	NitrogenDioxide []*float64 `json:"nitrogen_dioxide"`
+	SulphurDioxide  []*float64 `json:"sulphur_dioxide"`
+	CarbonMonoxide  []*float64 `json:"carbon_monoxide"`
+	EuropeanAqi     []*float64 `json:"european_aqi"`
+	AlderPollen     []*float64 `json:"alder_pollen"`
+	BirchPollen     []*float64 `json:"birch_pollen"`
+	GrassPollen     []*float64 `json:"grass_pollen"`
+	MugwortPollen   []*float64 `json:"mugwort_pollen"`
+	OlivePollen     []*float64 `json:"olive_pollen"`
+	RagweedPollen   []*float64 `json:"ragweed_pollen"`
+}
+
+// omAirQualityResponse is the raw Open-Meteo air quality payload
+type omAirQualityResponse struct {
+	Current struct {
+		Time            string   `json:"time"`
+		Pm25            *float64 `json:"pm2_5"`
+		Pm10            *float64 `json:"pm10"`
+		Ozone           *float64 `json:"ozone"`
+		NitrogenDioxide *float64 `json:"nitrogen_dioxide"`
+		SulphurDioxide  *float64 `json:"sulphur_dioxide"`
+		CarbonMonoxide  *float64 `json:"carbon_monoxide"`
+		EuropeanAqi     *float64 `json:"european_aqi"`
+	} `json:"current"`
+	Hourly omHourlyAirQuality `json:"hourly"`
+}
+
+func fetchAirQualityForecastData(ctx context.Context, latitude, longitude string) (omAirQualityResponse, error) {
+	urlStr := config.OmAirQualityUrl + "?latitude=" + latitude + "&longitude=" + longitude +
+		"&current=pm2_5,pm10,ozone,nitrogen_dioxide,sulphur_dioxide,carbon_monoxide,european_aqi" +
+		"&hourly=pm2_5,pm10,ozone,nitrogen_dioxide,sulphur_dioxide,carbon_monoxide,european_aqi," +
+		"alder_pollen,birch_pollen,grass_pollen,mugwort_pollen,olive_pollen,ragweed_pollen" +
+		"&forecast_days=5&timezone=auto"
+
+	var response omAirQualityResponse
+	resp, err := upstream.Get(ctx, "openmeteo", "/v1/air-quality", urlStr)
+	if err != nil {
+		return response, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return response, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return response, fmt.Errorf("open-meteo air quality returned status %d", resp.StatusCode)
+	}
+
+	if err := json.Unmarshal(body, &response); err != nil {
+		return response, err
+	}
+	return response, nil
+}
+
+// GetAirQualityDetails returns current, hourly and daily air quality.
+// Daily values are aggregated from the hourly series since Open-Meteo
+// does not provide a daily air quality forecast.
+func GetAirQualityDetails(ctx context.Context, latitude, longitude, language string) (models.AirQualityResult, error) {
+	latFloat, _ := strconv.ParseFloat(latitude, 64)
+	lonFloat, _ := strconv.ParseFloat(longitude, 64)
+
+	// Geolocation cached within an approximate range of 1.11 kilometers
+	cacheKey := fmt.Sprintf("air:openmeteo:%.2f:%.2f:%s", latFloat, lonFloat, language)
+	var cached models.AirQualityResult
+	if cache.Get(ctx, "openmeteo", cacheKey, &cached) {
+		return cached, nil
+	}
+
+	response, err := fetchAirQualityForecastData(ctx, latitude, longitude)
+	if err != nil {
+		return models.AirQualityResult{}, err
+	}
+
+	current := response.Current
+	airQualityResult := models.AirQualityResult{
+		Current: models.CurrentAirQualityResult{
+			Time: current.Time,
+			Pollutants: models.Pollutants{
+				Pm25:            valueOf(current.Pm25),
+				Pm10:            valueOf(current.Pm10),
+				Ozone:           valueOf(current.Ozone),
+				NitrogenDioxide: valueOf(current.NitrogenDioxide),
+				SulfurDioxide:   valueOf(current.SulphurDioxide),
+				CarbonMonoxide:  valueOf(current.CarbonMonoxide),
+			},
+			ProviderIndex: models.ProviderAQI{
+				Scale: omAirQualityScale,
+				Value: valueOf(current.EuropeanAqi),
+			},
+		},
+	}
+
+	hourly := response.Hourly
+	for i := 0; i < len(hourly.Time); i++ {
+		hour := models.HourlyAirQualityResult{
+			Time: hourly.Time[i],
+			Pollutants: models.Pollutants{
+				Pm25:            valueAt(hourly.Pm25, i),
+				Pm10:            valueAt(hourly.Pm10, i),
+				Ozone:           valueAt(hourly.Ozone, i),
+				NitrogenDioxide: valueAt(hourly.NitrogenDioxide, i),
+				SulfurDioxide:   valueAt(hourly.SulphurDioxide, i),
+				CarbonMonoxide:  valueAt(hourly.CarbonMonoxide, i),
+			},
+			ProviderIndex: models.ProviderAQI{
+				Scale: omAirQualityScale,
+				Value: valueAt(hourly.EuropeanAqi, i),
+			},
+		}
+
+		// Pollen is only modelled for Europe and only during the season
+		if hasValueAt(i, hourly.AlderPollen, hourly.BirchPollen, hourly.GrassPollen,
+			hourly.MugwortPollen, hourly.OlivePollen, hourly.RagweedPollen) {
+			hour.Pollen = &models.PollenConcentrations{
+				Alder:   valueAt(hourly.AlderPollen, i),
+				Birch:   valueAt(hourly.BirchPollen, i),
+				Grass:   valueAt(hourly.GrassPollen, i),
+				Mugwort: valueAt(hourly.MugwortPollen, i),
+				Olive:   valueAt(hourly.OlivePollen, i),
+				Ragweed: valueAt(hourly.RagweedPollen, i),
+			}
+		}
+		airQualityResult.Hourly = append(airQualityResult.Hourly, hour)
+	}
+
+	airQualityResult.Daily = aggregateDailyAirQuality(response.Hourly)
+	airQualityResult.Pollen = aggregateDailyPollen(airQualityResult.Hourly)
+
+	cache.Set(ctx, "openmeteo", cacheKey, airQualityResult, config.CacheTTL)
+
+	return airQualityResult, nil
+}
+
+// aggregateDailyAirQuality averages hourly concentrations per calendar day
+// and keeps the worst provider index of the day. Null hours are skipped per
+// pollutant rather than counted as zero, since the last hours of the
+// forecast are often missing.
+func aggregateDailyAirQuality(hourly omHourlyAirQuality) []models.DailyAirQualityResult {
+	series := [][]*float64{hourly.Pm25, hourly.Pm10, hourly.Ozone,
+		hourly.NitrogenDioxide, hourly.SulphurDioxide, hourly.CarbonMonoxide}
+
+	var days []models.DailyAirQualityResult
+	sums := make([]float64, len(series))
+	counts := make([]int, len(series))
+	mean := func(j int) float64 {
+		if counts[j] == 0 {
+			return 0
+		}
+		return sums[j] / float64(counts[j])
+	}
+
+	flush := func() {
+		if len(days) == 0 {
+			return
+		}
+		days[len(days)-1].Pollutants = models.Pollutants{
+			Pm25:            mean(0),
+			Pm10:            mean(1),
+			Ozone:           mean(2),
+			NitrogenDioxide: mean(3),
+			SulfurDioxide:   mean(4),
+			CarbonMonoxide:  mean(5),
+		}
+		clear(sums)
+		clear(counts)
+	}
+
+	for i, hour := range hourly.Time {
+		if len(hour) < 10 {
+			continue
+		}
+		date := hour[:10]
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			flush()
+			days = append(days, models.DailyAirQualityResult{
+				Date:          date,
+				ProviderIndex: models.ProviderAQI{Scale: omAirQualityScale},
+			})
+		}
+
+		day := &days[len(days)-1]
+		if index := valueAt(hourly.EuropeanAqi, i); index > day.ProviderIndex.Value {
+			day.ProviderIndex.Value = index
+		}
+		for j, values := range series {
+			if i < len(values) && values[i] != nil {
+				sums[j] += *values[i]
+				counts[j]++
+			}
+		}
+	}
+	flush()
+
+	return days
+}
+
+// aggregateDailyPollen rates each day by the peak hourly count of every species
+func aggregateDailyPollen(hours []models.HourlyAirQualityResult) []models.DailyPollenResult {
+	var days []models.DailyPollenResult
+	for _, hour := range hours {
+		if hour.Pollen == nil || len(hour.Time) < 10 {
+			continue
+		}
+		date := hour.Time[:10]
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, models.DailyPollenResult{
+				Date: date,
+				Species: []models.PollenSpecies{
+					{Species: "alder"}, {Species: "birch"}, {Species: "grass"},
+					{Species: "mugwort"}, {Species: "olive"}, {Species: "ragweed"},
+				},
+			})
+		}
+
+		day := &days[len(days)-1]
+		counts := []float64{hour.Pollen.Alder, hour.Pollen.Birch, hour.Pollen.Grass,
+			hour.Pollen.Mugwort, hour.Pollen.Olive, hour.Pollen.Ragweed}
+		for i, count := range counts {
+			if count > day.Species[i].Concentration {
+				day.Species[i].Concentration = count
+			}
+		}
+	}
+
+	for i := range days {
+		day := &days[i]
+		for j := range day.Species {
+			level := utils.ToPollenLevel(day.Species[j].Species, day.Species[j].Concentration)
+			day.Species[j].Level = utils.PollenLevelName(level)
+			if level > day.RiskLevel {
+				day.RiskLevel = level
+			}
+		}
+		day.Risk = utils.PollenLevelName(day.RiskLevel)
+	}
+	return days
+}
+
+// Dereference a nullable value, treating null as zero
+func valueOf(v *float64) float64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+// Retrieve a nullable value from an array based on an index
+func valueAt(arr []*float64, index int) float64 {
+	if index < len(arr) {
+		return valueOf(arr[index])
+	}
+	return 0
+}
+
+// Report whether any of the arrays has a non-null value at the index
+func hasValueAt(index int, arrays ...[]*float64) bool {
+	for _, arr := range arrays {
+		if index < len(arr) && arr[index] != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package openmeteo
+
+import (
+	"Zephyr/internal/cache"
+	"Zephyr/internal/config"
+	"Zephyr/internal/logging"
+	"Zephyr/internal/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// decodeLocations decodes a response for one or more coordinates. Open-Meteo
+// answers a multi-coordinate request with an array in request order, and a
+// single coordinate with a plain object.
+func decodeLocations(data []byte, count int) ([]map[string]interface{}, error) {
+	var locations []map[string]interface{}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &locations); err != nil {
+			return nil, err
+		}
+	} else {
+		var location map[string]interface{}
+		if err := json.Unmarshal(trimmed, &location); err != nil {
+			return nil, err
+		}
+		if reason, ok := location["reason"].(string); ok && location["error"] == true {
+			return nil, errors.New(reason)
+		}
+		locations = append(locations, location)
+	}
+
+	if len(locations) != count {
+		return nil, fmt.Errorf("open-meteo returned %d locations, expected %d", len(locations), count)
+	}
+	return locations, nil
+}
+
+// GetBatchForecastDetails fetches forecasts for several coordinates with a
+// single multi-coordinate request per API. Results and errors are in the
+// order of the coordinates; cached locations are not requested again.
+func GetBatchForecastDetails(ctx context.Context, coordinates []models.Coordinates, language, unit string) ([]models.WeatherResult, []error) {
+	results := make([]models.WeatherResult, len(coordinates))
+	errs := make([]error, len(coordinates))
+
+	var missing []int
+	var latitudes, longitudes []string
+	for i, coordinate := range coordinates {
+		// Geolocation cached within an approximate range of 1.11 kilometers
+		cacheKey := fmt.Sprintf("weather:openmeteo:%.2f:%.2f:%s:%s", coordinate.Latitude, coordinate.Longitude, language, unit)
+		if cache.Get(ctx, "openmeteo", cacheKey, &results[i]) {
+			continue
+		}
+		missing = append(missing, i)
+		latitudes = append(latitudes, fmt.Sprintf("%.4f", coordinate.Latitude))
+		longitudes = append(longitudes, fmt.Sprintf("%.4f", coordinate.Longitude))
+	}
+	if len(missing) == 0 {
+		return results, errs
+	}
+
+	fail := func(err error) ([]models.WeatherResult, []error) {
+		for _, i := range missing {
+			errs[i] = err
+		}
+		return results, errs
+	}
+
+	latitude, longitude := strings.Join(latitudes, ","), strings.Join(longitudes, ",")
+	weatherData, err := fetchWeatherData(ctx, latitude, longitude, language, unit)
+	if err != nil {
+		return fail(err)
+	}
+	airQualityData, err := fetchAirQualityData(ctx, latitude, longitude)
+	if err != nil {
+		return fail(err)
+	}
+
+	weatherMaps, err := decodeLocations(weatherData, len(missing))
+	if err != nil {
+		return fail(err)
+	}
+	// Air quality is optional; forecasts are still returned without it
+	airQualityMaps, err := decodeLocations(airQualityData, len(missing))
+	if err != nil {
+		logging.FromContext(ctx).Warn("Failed to decode batch air quality data", zap.String("provider", "openmeteo"), zap.Error(err))
+		airQualityMaps = make([]map[string]interface{}, len(missing))
+	}
+
+	for j, i := range missing {
+		results[i] = toWeatherResult(weatherMaps[j], airQualityMaps[j])
+
+		cacheKey := fmt.Sprintf("weather:openmeteo:%.2f:%.2f:%s:%s", coordinates[i].Latitude, coordinates[i].Longitude, language, unit)
+		cache.Set(ctx, "openmeteo", cacheKey, results[i], config.CacheTTL)
+	}
+	return results, errs
+}
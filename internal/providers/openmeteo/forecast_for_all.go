@@ -1,23 +1,24 @@
 package openmeteo
 
 import (
+	"Zephyr/internal/cache"
 	"Zephyr/internal/config"
 	"Zephyr/internal/models"
+	"Zephyr/internal/upstream"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
-	"net/http"
 	"strconv"
 )
 
-func fetchWeatherData(latitude, longitude, language, unit string) ([]byte, error) {
+func fetchWeatherData(ctx context.Context, latitude, longitude, language, unit string) ([]byte, error) {
 	urlStr := config.OmForcastUrl + "?latitude=" + latitude + "&longitude=" + longitude +
 		"&current=apparent_temperature,temperature_2m,weather_code,relative_humidity_2m,wind_speed_10m,winddirection_10m,surface_pressure" +
 		"&hourly=weather_code,temperature_2m,precipitation,visibility,wind_speed_10m,wind_speed_80m,wind_speed_120m,pressure_msl,surface_pressure" +
 		"&daily=temperature_2m_max,temperature_2m_min,weather_code,uv_index_max" +
 		"&timezone=auto" + "&lang=" + language + "&temperature_unit=" + unit
-	resp, err := http.Get(urlStr)
+	resp, err := upstream.Get(ctx, "openmeteo", "/v1/forecast", urlStr)
 	if err != nil {
 		return nil, err
 	}
@@ -30,11 +31,11 @@ func fetchWeatherData(latitude, longitude, language, unit string) ([]byte, error
 	return body, nil
 }
 
-func fetchAirQualityData(latitude, longitude string) ([]byte, error) {
+func fetchAirQualityData(ctx context.Context, latitude, longitude string) ([]byte, error) {
 	urlStr := config.OmAirQualityUrl + "?latitude=" + latitude + "&longitude=" + longitude +
 		"&current=pm2_5,pm10,ozone,nitrogen_dioxide,sulphur_dioxide,european_aqi" +
 		"&timezone=auto"
-	resp, err := http.Get(urlStr)
+	resp, err := upstream.Get(ctx, "openmeteo", "/v1/air-quality", urlStr)
 	if err != nil {
 		return nil, err
 	}
@@ -47,7 +48,7 @@ func fetchAirQualityData(latitude, longitude string) ([]byte, error) {
 	return body, nil
 }
 
-func GetAllForecastDetails(latitude, longitude, language, unit string) models.WeatherResult {
+func GetAllForecastDetails(ctx context.Context, latitude, longitude, language, unit string) models.WeatherResult {
 	// convert to float64
 	latFloat, _ := strconv.ParseFloat(latitude, 64)
 	lonFloat, _ := strconv.ParseFloat(longitude, 64)
@@ -57,26 +58,20 @@ func GetAllForecastDetails(latitude, longitude, language, unit string) models.We
 	cacheLongitude := fmt.Sprintf("%.2f", lonFloat)
 
 	cacheKey := fmt.Sprintf("weather:openmeteo:%s:%s:%s:%s", cacheLatitude, cacheLongitude, language, unit)
-	if cachedData, err := config.RedisClient.Get(config.Ctx, cacheKey).Result(); err == nil {
-		var weatherResult models.WeatherResult
-		err := json.Unmarshal([]byte(cachedData), &weatherResult)
-		if err == nil {
-			log.Printf("Retrieved weather data from cache: %s\n", cacheKey)
-			return weatherResult
-		}
+	var cached models.WeatherResult
+	if cache.Get(ctx, "openmeteo", cacheKey, &cached) {
+		return cached
 	}
-	weatherData, err := fetchWeatherData(latitude, longitude, language, unit)
+	weatherData, err := fetchWeatherData(ctx, latitude, longitude, language, unit)
 	if err != nil {
 		return models.WeatherResult{}
 	}
 
-	airQualityData, err := fetchAirQualityData(latitude, longitude)
+	airQualityData, err := fetchAirQualityData(ctx, latitude, longitude)
 	if err != nil {
 		return models.WeatherResult{}
 	}
 
-	var weatherResult models.WeatherResult
-
 	var weatherMap map[string]interface{}
 	err = json.Unmarshal(weatherData, &weatherMap)
 	if err != nil {
@@ -89,8 +84,20 @@ func GetAllForecastDetails(latitude, longitude, language, unit string) models.We
 		return models.WeatherResult{}
 	}
 
+	weatherResult := toWeatherResult(weatherMap, airQualityMap)
+	cache.Set(ctx, "openmeteo", cacheKey, weatherResult, config.CacheTTL)
+
+	return weatherResult
+}
+
+// toWeatherResult converts one location of the forecast and air quality responses
+func toWeatherResult(weatherMap, airQualityMap map[string]interface{}) models.WeatherResult {
+	var weatherResult models.WeatherResult
+
 	if current, ok := weatherMap["current"].(map[string]interface{}); ok {
+		currentTime, _ := current["time"].(string)
 		currentWeather := models.CurrentWeatherResult{
+			Time:                currentTime,
 			Temperature:         getFloatValue(current, "temperature_2m"),
 			WeatherCode:         getIntValue(current, "weather_code"),
 			WindSpeed:           getFloatValue(current, "wind_speed_10m"),
@@ -156,10 +163,6 @@ func GetAllForecastDetails(latitude, longitude, language, unit string) models.We
 			weatherResult.DWR = append(weatherResult.DWR, dailyWeather)
 		}
 	}
-	if cachedData, err := json.Marshal(weatherResult); err == nil {
-		log.Printf("Cached weather data: %s\n", cacheKey)
-		config.RedisClient.Set(config.Ctx, cacheKey, cachedData, config.CacheTTL)
-	}
 
 	return weatherResult
 }
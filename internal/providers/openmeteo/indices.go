@@ -0,0 +1,152 @@
+package openmeteo
+
+import (
+	"Zephyr/internal/models"
+	"context"
+	"errors"
+	"strings"
+)
+
+// Wind speed (km/h) above which dressing advice assumes an extra layer
+const dressingWindThreshold = 20
+
+// GetLifestyleIndices computes today's lifestyle indices from the cached
+// forecast, since Open-Meteo has no indices API. Texts are in English.
+func GetLifestyleIndices(ctx context.Context, latitude, longitude, language string) ([]models.LifestyleIndex, error) {
+	weatherResult := GetAllForecastDetails(ctx, latitude, longitude, language, "celsius")
+	if len(weatherResult.DWR) == 0 {
+		return nil, errors.New("failed to fetch forecast for indices")
+	}
+
+	today := weatherResult.DWR[0]
+	current := weatherResult.CWR
+
+	precipitation := upcomingPrecipitation(weatherResult.HWR, current.Time)
+
+	return []models.LifestyleIndex{
+		dressingIndex(today.Date, current.ApparentTemperature, current.WindSpeed),
+		uvIndex(today.Date, today.UvIndexMax),
+		runningIndex(today.Date, current.Temperature, current.Humidity, current.AQI),
+		carWashIndex(today.Date, precipitation),
+		fluIndex(today.Date, today.TempMax-today.TempMin),
+	}, nil
+}
+
+// upcomingPrecipitation sums the rain (mm) of the 48 hours starting with
+// the current one, so rain that already fell today is not counted. Hourly
+// times and now are both local ISO 8601 times like "2026-03-01T14:00";
+// without a current time the sum starts at the first hour.
+func upcomingPrecipitation(hours []models.HourlyWeatherResult, now string) float64 {
+	start := 0
+	if len(now) >= 13 {
+		for start < len(hours) && hours[start].Time < now[:13] {
+			start++
+		}
+	}
+
+	precipitation := 0.0
+	for _, hour := range hours[start:min(start+48, len(hours))] {
+		precipitation += hour.Precipitation
+	}
+	return precipitation
+}
+
+// dressingIndex rates clothing weight from apparent temperature (°C),
+// treating strong wind as two degrees colder
+func dressingIndex(date string, apparentTemperature, windSpeed float64) models.LifestyleIndex {
+	effective := apparentTemperature
+	if windSpeed > dressingWindThreshold {
+		effective -= 2
+	}
+
+	index := models.LifestyleIndex{Date: date, Type: "dressing", Name: "Dressing Index", Computed: true}
+	switch {
+	case effective >= 28:
+		index.Level, index.Category, index.Text = 1, "Hot", "Wear light, breathable clothing such as T-shirts and shorts."
+	case effective >= 23:
+		index.Level, index.Category, index.Text = 2, "Warm", "Short sleeves or a thin shirt are comfortable."
+	case effective >= 18:
+		index.Level, index.Category, index.Text = 3, "Comfortable", "A long-sleeved shirt or light sweater is suitable."
+	case effective >= 10:
+		index.Level, index.Category, index.Text = 4, "Cool", "Wear a jacket or sweater."
+	case effective >= 0:
+		index.Level, index.Category, index.Text = 5, "Cold", "Wear a warm coat; consider a hat and gloves."
+	default:
+		index.Level, index.Category, index.Text = 6, "Very Cold", "Wear a heavy winter coat, hat, scarf and gloves."
+	}
+	return index
+}
+
+// uvIndex follows the WHO UV index exposure categories
+func uvIndex(date string, uvIndexMax float64) models.LifestyleIndex {
+	index := models.LifestyleIndex{Date: date, Type: "uv", Name: "UV Index", Computed: true}
+	switch {
+	case uvIndexMax < 3:
+		index.Level, index.Category, index.Text = 1, "Low", "No protection needed for most people."
+	case uvIndexMax < 6:
+		index.Level, index.Category, index.Text = 2, "Moderate", "Wear sunglasses and use sunscreen around midday."
+	case uvIndexMax < 8:
+		index.Level, index.Category, index.Text = 3, "High", "Seek shade at midday and apply SPF 30+ sunscreen."
+	case uvIndexMax < 11:
+		index.Level, index.Category, index.Text = 4, "Very High", "Avoid the sun between late morning and mid-afternoon."
+	default:
+		index.Level, index.Category, index.Text = 5, "Extreme", "Avoid being outside during midday hours."
+	}
+	return index
+}
+
+// runningIndex counts how many of temperature, humidity and air quality
+// (european_aqi) fall outside comfortable running conditions
+func runningIndex(date string, temperature, humidity, aqi float64) models.LifestyleIndex {
+	var reasons []string
+	if temperature < 5 || temperature > 25 {
+		reasons = append(reasons, "temperature")
+	}
+	if humidity > 80 {
+		reasons = append(reasons, "humidity")
+	}
+	if aqi > 40 {
+		reasons = append(reasons, "air quality")
+	}
+
+	index := models.LifestyleIndex{Date: date, Type: "running", Name: "Running Index", Computed: true}
+	switch len(reasons) {
+	case 0:
+		index.Level, index.Category, index.Text = 1, "Suitable", "Conditions are good for running outdoors."
+	case 1:
+		index.Level, index.Category = 2, "Moderately Suitable"
+		index.Text = "Running is possible but mind the " + reasons[0] + "."
+	default:
+		index.Level, index.Category = 3, "Unsuitable"
+		index.Text = "Consider exercising indoors because of " + strings.Join(reasons, " and ") + "."
+	}
+	return index
+}
+
+// carWashIndex rates car washing by the rain (mm) expected in the next 48 hours
+func carWashIndex(date string, precipitation float64) models.LifestyleIndex {
+	index := models.LifestyleIndex{Date: date, Type: "car_wash", Name: "Car Wash Index", Computed: true}
+	switch {
+	case precipitation == 0:
+		index.Level, index.Category, index.Text = 1, "Suitable", "No rain expected in the next two days."
+	case precipitation < 2:
+		index.Level, index.Category, index.Text = 2, "Moderately Suitable", "Light rain is possible in the next two days."
+	default:
+		index.Level, index.Category, index.Text = 3, "Unsuitable", "Rain is expected in the next two days."
+	}
+	return index
+}
+
+// fluIndex rates cold and flu risk from the daily temperature range (°C)
+func fluIndex(date string, temperatureRange float64) models.LifestyleIndex {
+	index := models.LifestyleIndex{Date: date, Type: "flu", Name: "Cold Risk Index", Computed: true}
+	switch {
+	case temperatureRange < 8:
+		index.Level, index.Category, index.Text = 1, "Low", "Small temperature swings; low risk of catching a cold."
+	case temperatureRange < 12:
+		index.Level, index.Category, index.Text = 2, "Moderate", "Noticeable temperature swings; adjust clothing through the day."
+	default:
+		index.Level, index.Category, index.Text = 3, "High", "Large temperature swings; take care to avoid catching a cold."
+	}
+	return index
+}
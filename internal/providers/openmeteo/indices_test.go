@@ -0,0 +1,172 @@
+package openmeteo
+
+import (
+	"Zephyr/internal/models"
+	"fmt"
+	"testing"
+)
+
+func TestDressingIndex(t *testing.T) {
+	cases := []struct {
+		name     string
+		apparent float64
+		wind     float64
+		level    int
+		category string
+	}{
+		{"hot at threshold", 28, 0, 1, "Hot"},
+		{"warm just below hot", 27.9, 0, 2, "Warm"},
+		{"warm at threshold", 23, 0, 2, "Warm"},
+		{"comfortable at threshold", 18, 0, 3, "Comfortable"},
+		{"cool at threshold", 10, 0, 4, "Cool"},
+		{"cold at zero", 0, 0, 5, "Cold"},
+		{"very cold below zero", -0.1, 0, 6, "Very Cold"},
+		{"wind at threshold keeps level", 18, 20, 3, "Comfortable"},
+		{"strong wind feels two degrees colder", 18, 21, 4, "Cool"},
+		{"strong wind pushes below zero", 1, 30, 6, "Very Cold"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			index := dressingIndex("2026-03-01", c.apparent, c.wind)
+			if index.Level != c.level || index.Category != c.category {
+				t.Errorf("dressingIndex(%v, %v) = %d %q, want %d %q", c.apparent, c.wind, index.Level, index.Category, c.level, c.category)
+			}
+		})
+	}
+}
+
+func TestUVIndex(t *testing.T) {
+	cases := []struct {
+		uv       float64
+		level    int
+		category string
+	}{
+		{0, 1, "Low"},
+		{2.9, 1, "Low"},
+		{3, 2, "Moderate"},
+		{5.9, 2, "Moderate"},
+		{6, 3, "High"},
+		{8, 4, "Very High"},
+		{10.9, 4, "Very High"},
+		{11, 5, "Extreme"},
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprint(c.uv), func(t *testing.T) {
+			index := uvIndex("2026-03-01", c.uv)
+			if index.Level != c.level || index.Category != c.category {
+				t.Errorf("uvIndex(%v) = %d %q, want %d %q", c.uv, index.Level, index.Category, c.level, c.category)
+			}
+		})
+	}
+}
+
+func TestRunningIndex(t *testing.T) {
+	cases := []struct {
+		name        string
+		temperature float64
+		humidity    float64
+		aqi         float64
+		level       int
+		text        string
+	}{
+		{"all at limits", 5, 80, 40, 1, "Conditions are good for running outdoors."},
+		{"upper temperature limit", 25, 50, 20, 1, "Conditions are good for running outdoors."},
+		{"too cold", 4.9, 50, 20, 2, "Running is possible but mind the temperature."},
+		{"too humid", 15, 81, 20, 2, "Running is possible but mind the humidity."},
+		{"poor air", 15, 50, 41, 2, "Running is possible but mind the air quality."},
+		{"hot and polluted", 26, 50, 41, 3, "Consider exercising indoors because of temperature and air quality."},
+		{"everything wrong", 30, 90, 60, 3, "Consider exercising indoors because of temperature and humidity and air quality."},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			index := runningIndex("2026-03-01", c.temperature, c.humidity, c.aqi)
+			if index.Level != c.level || index.Text != c.text {
+				t.Errorf("runningIndex = %d %q, want %d %q", index.Level, index.Text, c.level, c.text)
+			}
+		})
+	}
+}
+
+func TestCarWashIndex(t *testing.T) {
+	cases := []struct {
+		precipitation float64
+		level         int
+		category      string
+	}{
+		{0, 1, "Suitable"},
+		{0.1, 2, "Moderately Suitable"},
+		{1.9, 2, "Moderately Suitable"},
+		{2, 3, "Unsuitable"},
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprint(c.precipitation), func(t *testing.T) {
+			index := carWashIndex("2026-03-01", c.precipitation)
+			if index.Level != c.level || index.Category != c.category {
+				t.Errorf("carWashIndex(%v) = %d %q, want %d %q", c.precipitation, index.Level, index.Category, c.level, c.category)
+			}
+		})
+	}
+}
+
+func TestFluIndex(t *testing.T) {
+	cases := []struct {
+		temperatureRange float64
+		level            int
+		category         string
+	}{
+		{0, 1, "Low"},
+		{7.9, 1, "Low"},
+		{8, 2, "Moderate"},
+		{11.9, 2, "Moderate"},
+		{12, 3, "High"},
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprint(c.temperatureRange), func(t *testing.T) {
+			index := fluIndex("2026-03-01", c.temperatureRange)
+			if index.Level != c.level || index.Category != c.category {
+				t.Errorf("fluIndex(%v) = %d %q, want %d %q", c.temperatureRange, index.Level, index.Category, c.level, c.category)
+			}
+		})
+	}
+}
+
+func TestUpcomingPrecipitation(t *testing.T) {
+	// Three days of hours with 1 mm of rain in every morning hour of today
+	var hours []models.HourlyWeatherResult
+	for day := 1; day <= 3; day++ {
+		for hour := range 24 {
+			rain := 0.0
+			if day == 1 && hour < 12 {
+				rain = 1
+			}
+			if day == 3 && hour == 23 {
+				rain = 5
+			}
+			hours = append(hours, models.HourlyWeatherResult{
+				Time:          fmt.Sprintf("2026-03-%02dT%02d:00", day, hour),
+				Precipitation: rain,
+			})
+		}
+	}
+
+	cases := []struct {
+		name string
+		now  string
+		want float64
+	}{
+		{"morning rain still ahead", "2026-03-01T09:15", 3},
+		{"current hour counts", "2026-03-01T11:45", 1},
+		{"rain earlier today is ignored", "2026-03-01T14:00", 0},
+		{"window ends after 48 hours", "2026-03-01T23:00", 0},
+		{"window reaches the last hour", "2026-03-02T00:00", 5},
+		{"no current time starts at midnight", "", 12},
+		{"after the last hour", "2026-03-04T00:00", 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := upcomingPrecipitation(hours, c.now); got != c.want {
+				t.Errorf("upcomingPrecipitation(%q) = %v, want %v", c.now, got, c.want)
+			}
+		})
+	}
+}
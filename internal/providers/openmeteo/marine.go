@@ -0,0 +1,153 @@
+package openmeteo
+
+import (
+	"Zephyr/internal/cache"
+	"Zephyr/internal/config"
+	"Zephyr/internal/models"
+	"Zephyr/internal/upstream"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrNoMarineData is returned for points the marine model does not cover,
+// which in practice means the coordinates are inland
+var ErrNoMarineData = errors.New("no marine data for this location, it may be inland")
+
+// ErrInvalidMarineRequest is returned when Open-Meteo rejects the request
+// parameters, such as out of range coordinates
+var ErrInvalidMarineRequest = errors.New("invalid marine request")
+
+// omNoDataReason is the reason Open-Meteo gives for points outside the
+// marine grid
+const omNoDataReason = "No data is available for this location"
+
+type omMarineResponse struct {
+	Reason string `json:"reason"`
+	Hourly struct {
+		Time                  []string   `json:"time"`
+		WaveHeight            []*float64 `json:"wave_height"`
+		WaveDirection         []*float64 `json:"wave_direction"`
+		WavePeriod            []*float64 `json:"wave_period"`
+		WindWaveHeight        []*float64 `json:"wind_wave_height"`
+		SwellWaveHeight       []*float64 `json:"swell_wave_height"`
+		SwellWaveDirection    []*float64 `json:"swell_wave_direction"`
+		SwellWavePeriod       []*float64 `json:"swell_wave_period"`
+		SeaSurfaceTemperature []*float64 `json:"sea_surface_temperature"`
+		SeaLevelHeightMsl     []*float64 `json:"sea_level_height_msl"`
+	} `json:"hourly"`
+	Daily struct {
+		Time                  []string   `json:"time"`
+		WaveHeightMax         []*float64 `json:"wave_height_max"`
+		WaveDirectionDominant []*float64 `json:"wave_direction_dominant"`
+		WavePeriodMax         []*float64 `json:"wave_period_max"`
+		SwellWaveHeightMax    []*float64 `json:"swell_wave_height_max"`
+		SwellWavePeriodMax    []*float64 `json:"swell_wave_period_max"`
+	} `json:"daily"`
+}
+
+func fetchMarineData(ctx context.Context, latitude, longitude string) (omMarineResponse, error) {
+	urlStr := config.OmMarineUrl + "?latitude=" + latitude + "&longitude=" + longitude +
+		"&hourly=wave_height,wave_direction,wave_period,wind_wave_height,swell_wave_height," +
+		"swell_wave_direction,swell_wave_period,sea_surface_temperature,sea_level_height_msl" +
+		"&daily=wave_height_max,wave_direction_dominant,wave_period_max,swell_wave_height_max,swell_wave_period_max" +
+		"&timezone=auto"
+
+	var response omMarineResponse
+	resp, err := upstream.Get(ctx, "openmeteo", "/v1/marine", urlStr)
+	if err != nil {
+		return response, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return response, err
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return response, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		// Open-Meteo rejects points outside the marine grid with a reason,
+		// other client errors mean the request itself was malformed
+		if strings.HasPrefix(response.Reason, omNoDataReason) {
+			return response, fmt.Errorf("%w: %s", ErrNoMarineData, response.Reason)
+		}
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && response.Reason != "" {
+			return response, fmt.Errorf("%w: %s", ErrInvalidMarineRequest, response.Reason)
+		}
+		return response, fmt.Errorf("open-meteo marine returned status %d", resp.StatusCode)
+	}
+	return response, nil
+}
+
+// GetMarineDetails returns hourly and daily wave, swell and sea level data
+func GetMarineDetails(ctx context.Context, latitude, longitude string) (models.MarineResult, error) {
+	latFloat, _ := strconv.ParseFloat(latitude, 64)
+	lonFloat, _ := strconv.ParseFloat(longitude, 64)
+
+	// Geolocation cached within an approximate range of 1.11 kilometers
+	cacheKey := fmt.Sprintf("marine:openmeteo:%.2f:%.2f", latFloat, lonFloat)
+	var cached models.MarineResult
+	if cache.Get(ctx, "openmeteo", cacheKey, &cached) {
+		return cached, nil
+	}
+
+	response, err := fetchMarineData(ctx, latitude, longitude)
+	if err != nil {
+		return models.MarineResult{}, err
+	}
+
+	// Inland points come back with every wave value null
+	hourly := response.Hourly
+	if !hasAnyValue(hourly.WaveHeight) {
+		return models.MarineResult{}, ErrNoMarineData
+	}
+
+	var marineResult models.MarineResult
+	for i := 0; i < len(hourly.Time); i++ {
+		marineResult.Hourly = append(marineResult.Hourly, models.HourlyMarineResult{
+			Time:                  hourly.Time[i],
+			WaveHeight:            valueAt(hourly.WaveHeight, i),
+			WaveDirection:         valueAt(hourly.WaveDirection, i),
+			WavePeriod:            valueAt(hourly.WavePeriod, i),
+			WindWaveHeight:        valueAt(hourly.WindWaveHeight, i),
+			SwellWaveHeight:       valueAt(hourly.SwellWaveHeight, i),
+			SwellWaveDirection:    valueAt(hourly.SwellWaveDirection, i),
+			SwellWavePeriod:       valueAt(hourly.SwellWavePeriod, i),
+			SeaSurfaceTemperature: valueAt(hourly.SeaSurfaceTemperature, i),
+			SeaLevelHeight:        valueAt(hourly.SeaLevelHeightMsl, i),
+		})
+	}
+
+	daily := response.Daily
+	for i := 0; i < len(daily.Time); i++ {
+		marineResult.Daily = append(marineResult.Daily, models.DailyMarineResult{
+			Date:                  daily.Time[i],
+			WaveHeightMax:         valueAt(daily.WaveHeightMax, i),
+			WaveDirectionDominant: valueAt(daily.WaveDirectionDominant, i),
+			WavePeriodMax:         valueAt(daily.WavePeriodMax, i),
+			SwellWaveHeightMax:    valueAt(daily.SwellWaveHeightMax, i),
+			SwellWavePeriodMax:    valueAt(daily.SwellWavePeriodMax, i),
+		})
+	}
+
+	cache.Set(ctx, "openmeteo", cacheKey, marineResult, config.CacheTTL)
+
+	return marineResult, nil
+}
+
+// Report whether the array has at least one non-null value
+func hasAnyValue(arr []*float64) bool {
+	for _, v := range arr {
+		if v != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package openmeteo
+
+import (
+	"Zephyr/internal/cache"
+	"Zephyr/internal/config"
+	"Zephyr/internal/models"
+	"Zephyr/internal/upstream"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Nowcasts go stale much faster than forecasts, so they get their own TTL
+const nowcastCacheTTL = 5 * time.Minute
+
+type omNowcastResponse struct {
+	Minutely15 struct {
+		Time          []string   `json:"time"`
+		Precipitation []*float64 `json:"precipitation"`
+	} `json:"minutely_15"`
+}
+
+func fetchNowcastData(ctx context.Context, latitude, longitude string) (omNowcastResponse, error) {
+	// Times are requested in UTC so callers can compare them with the clock
+	urlStr := config.OmForcastUrl + "?latitude=" + latitude + "&longitude=" + longitude +
+		"&minutely_15=precipitation&forecast_minutely_15=8&timezone=GMT"
+
+	var response omNowcastResponse
+	resp, err := upstream.Get(ctx, "openmeteo", "/v1/forecast", urlStr)
+	if err != nil {
+		return response, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return response, fmt.Errorf("open-meteo nowcast returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return response, err
+	}
+	err = json.Unmarshal(body, &response)
+	return response, err
+}
+
+// GetNowcast returns precipitation for the next two hours in 15 minute steps
+func GetNowcast(ctx context.Context, latitude, longitude string) (models.NowcastResult, error) {
+	latFloat, _ := strconv.ParseFloat(latitude, 64)
+	lonFloat, _ := strconv.ParseFloat(longitude, 64)
+
+	// Geolocation cached within an approximate range of 1.11 kilometers
+	cacheKey := fmt.Sprintf("nowcast:openmeteo:%.2f:%.2f", latFloat, lonFloat)
+	var cached models.NowcastResult
+	if cache.Get(ctx, "openmeteo", cacheKey, &cached) {
+		return cached, nil
+	}
+
+	response, err := fetchNowcastData(ctx, latitude, longitude)
+	if err != nil {
+		return models.NowcastResult{}, err
+	}
+
+	var nowcastResult models.NowcastResult
+	minutely := response.Minutely15
+	for i := 0; i < len(minutely.Time); i++ {
+		nowcastResult.Minutely = append(nowcastResult.Minutely, models.MinutelyPrecipitation{
+			Time:          minutely.Time[i],
+			Precipitation: valueAt(minutely.Precipitation, i),
+		})
+	}
+
+	cache.Set(ctx, "openmeteo", cacheKey, nowcastResult, nowcastCacheTTL)
+
+	return nowcastResult, nil
+}
@@ -0,0 +1,70 @@
+package osm
+
+import (
+	"Zephyr/internal/config"
+	"Zephyr/internal/models"
+	"Zephyr/internal/upstream"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// OsmID builds the typed OSM ID ("N123", "W123", "R123") used by Nominatim lookups
+func OsmID(osmType string, osmID int64) string {
+	if osmType == "" || osmID == 0 {
+		return ""
+	}
+	return strings.ToUpper(osmType[:1]) + strconv.FormatInt(osmID, 10)
+}
+
+// LookupPlace resolves a typed OSM ID. The boolean is false when Nominatim
+// does not know the ID.
+func LookupPlace(ctx context.Context, osmID, acceptLanguage string) (models.Location, bool, error) {
+	urlStr := config.OsmLookupUrl + "?format=json&addressdetails=1&osm_ids=" + url.QueryEscape(osmID) +
+		"&accept-language=" + acceptLanguage
+
+	resp, err := upstream.Get(ctx, "openstreetmap", "/lookup", urlStr)
+	if err != nil {
+		return models.Location{}, false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return models.Location{}, false, fmt.Errorf("nominatim lookup returned status %d", resp.StatusCode)
+	}
+
+	var places []struct {
+		Name    string `json:"name"`
+		Lat     string `json:"lat"`
+		Lon     string `json:"lon"`
+		Address struct {
+			State   string `json:"state"`
+			Country string `json:"country"`
+		} `json:"address"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&places); err != nil {
+		return models.Location{}, false, err
+	}
+	if len(places) == 0 {
+		return models.Location{}, false, nil
+	}
+
+	place := places[0]
+	latitude, err1 := strconv.ParseFloat(place.Lat, 64)
+	longitude, err2 := strconv.ParseFloat(place.Lon, 64)
+	if err1 != nil || err2 != nil {
+		return models.Location{}, false, fmt.Errorf("nominatim returned invalid coordinates for %s", osmID)
+	}
+
+	return models.Location{
+		ID:        "osm:" + osmID,
+		Name:      place.Name,
+		Latitude:  latitude,
+		Longitude: longitude,
+		State:     place.Address.State,
+		Country:   place.Address.Country,
+	}, true, nil
+}
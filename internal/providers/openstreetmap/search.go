@@ -2,14 +2,15 @@ package osm
 
 import (
 	"Zephyr/internal/config"
+	"Zephyr/internal/upstream"
+	"context"
 	"io"
-	"net/http"
 )
 
-func SearchCitiesFromOsm(query, acceptLanguage string) ([]byte, error) {
+func SearchCitiesFromOsm(ctx context.Context, query, acceptLanguage string) ([]byte, error) {
 	urlStr := config.OsmUrl + "?format=json&q=" + query + "&accept-language=" + acceptLanguage + "&limit=30&addressdetails=1&featureType=city"
 
-	resp, err := http.Get(urlStr)
+	resp, err := upstream.Get(ctx, "openstreetmap", "/search", urlStr)
 	if err != nil {
 		return nil, err
 	}
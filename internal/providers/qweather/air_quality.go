@@ -0,0 +1,347 @@
+package qweather
+
+import (
+	"Zephyr/internal/cache"
+	"Zephyr/internal/config"
+	"Zephyr/internal/logging"
+	"Zephyr/internal/models"
+	"Zephyr/pkg/utils"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// QWeather's v7 real-time AQI follows the China MEE standard
+const qwNowAirQualityScale = "cn-mee"
+
+// qAirQualityIndex is an index entry of the v1 air quality API
+type qAirQualityIndex struct {
+	Code             string        `json:"code"`
+	Aqi              StringFloat64 `json:"aqi"`
+	Category         string        `json:"category"`
+	PrimaryPollutant *struct {
+		Code string `json:"code"`
+	} `json:"primaryPollutant"`
+}
+
+// qAirQualityPollutant is a pollutant entry of the v1 air quality API
+type qAirQualityPollutant struct {
+	Code          string `json:"code"`
+	Concentration struct {
+		Value StringFloat64 `json:"value"`
+		Unit  string        `json:"unit"`
+	} `json:"concentration"`
+}
+
+func fetchCurrentAirQualityData(ctx context.Context, latitude, longitude, language string) (models.CurrentAirQualityResult, error) {
+	type qAirNowResponse struct {
+		UpdateTime string `json:"updateTime"`
+		Now        struct {
+			PubTime  string        `json:"pubTime"`
+			Aqi      StringFloat64 `json:"aqi"`
+			Category string        `json:"category"`
+			Primary  string        `json:"primary"`
+			Pm10     StringFloat64 `json:"pm10"`
+			Pm2p5    StringFloat64 `json:"pm2p5"`
+			No2      StringFloat64 `json:"no2"`
+			So2      StringFloat64 `json:"so2"`
+			Co       StringFloat64 `json:"co"`
+			O3       StringFloat64 `json:"o3"`
+		} `json:"now"`
+	}
+
+	var response qAirNowResponse
+	apiURL := fmt.Sprintf("%s/v7/air/now?location=%s,%s&lang=%s", config.QweatherUrl, longitude, latitude, language)
+	if err := fetchAPI(ctx, "/v7/air/now", apiURL, &response); err != nil {
+		return models.CurrentAirQualityResult{}, err
+	}
+
+	now := response.Now
+	return models.CurrentAirQualityResult{
+		Time: now.PubTime,
+		Pollutants: models.Pollutants{
+			Pm25:            float64(now.Pm2p5),
+			Pm10:            float64(now.Pm10),
+			Ozone:           float64(now.O3),
+			NitrogenDioxide: float64(now.No2),
+			SulfurDioxide:   float64(now.So2),
+			// v7 reports CO in mg/m³
+			CarbonMonoxide: float64(now.Co) * 1000,
+		},
+		PrimaryPollutant: toPollutantName(now.Primary),
+		ProviderIndex: models.ProviderAQI{
+			Scale:    qwNowAirQualityScale,
+			Value:    float64(now.Aqi),
+			Category: now.Category,
+		},
+	}, nil
+}
+
+func fetchHourlyAirQualityData(ctx context.Context, latitude, longitude, language string) ([]models.HourlyAirQualityResult, error) {
+	type qAirHourlyResponse struct {
+		Hours []struct {
+			ForecastTime string                 `json:"forecastTime"`
+			Indexes      []qAirQualityIndex     `json:"indexes"`
+			Pollutants   []qAirQualityPollutant `json:"pollutants"`
+		} `json:"hours"`
+	}
+
+	var response qAirHourlyResponse
+	apiURL := fmt.Sprintf("%s/airquality/v1/hourly/%s/%s?lang=%s", config.QweatherUrl, latitude, longitude, language)
+	if err := fetchAPI(ctx, "/airquality/v1/hourly/{lat}/{lon}", apiURL, &response); err != nil {
+		return nil, err
+	}
+
+	hours := make([]models.HourlyAirQualityResult, 0, len(response.Hours))
+	for _, hour := range response.Hours {
+		providerIndex, primary := toProviderIndex(hour.Indexes)
+		hours = append(hours, models.HourlyAirQualityResult{
+			Time:             hour.ForecastTime,
+			Pollutants:       toPollutants(ctx, hour.Pollutants),
+			PrimaryPollutant: primary,
+			ProviderIndex:    providerIndex,
+		})
+	}
+	return hours, nil
+}
+
+func fetchDailyAirQualityData(ctx context.Context, latitude, longitude, language string) ([]models.DailyAirQualityResult, error) {
+	type qAirDailyResponse struct {
+		Days []struct {
+			ForecastStartTime string                 `json:"forecastStartTime"`
+			Indexes           []qAirQualityIndex     `json:"indexes"`
+			Pollutants        []qAirQualityPollutant `json:"pollutants"`
+		} `json:"days"`
+	}
+
+	var response qAirDailyResponse
+	apiURL := fmt.Sprintf("%s/airquality/v1/daily/%s/%s?lang=%s", config.QweatherUrl, latitude, longitude, language)
+	if err := fetchAPI(ctx, "/airquality/v1/daily/{lat}/{lon}", apiURL, &response); err != nil {
+		return nil, err
+	}
+
+	days := make([]models.DailyAirQualityResult, 0, len(response.Days))
+	for _, day := range response.Days {
+		date := day.ForecastStartTime
+		if len(date) >= 10 {
+			date = date[:10]
+		}
+		providerIndex, primary := toProviderIndex(day.Indexes)
+		days = append(days, models.DailyAirQualityResult{
+			Date:             date,
+			Pollutants:       toPollutants(ctx, day.Pollutants),
+			PrimaryPollutant: primary,
+			ProviderIndex:    providerIndex,
+		})
+	}
+	return days, nil
+}
+
+// fetchPollenData reads the allergy index (type 7), which QWeather only
+// provides for locations in China
+func fetchPollenData(ctx context.Context, latitude, longitude, language string) ([]models.DailyPollenResult, error) {
+	type qIndicesResponse struct {
+		Daily []struct {
+			Date     string    `json:"date"`
+			Level    StringInt `json:"level"`
+			Category string    `json:"category"`
+			Text     string    `json:"text"`
+		} `json:"daily"`
+	}
+
+	var response qIndicesResponse
+	apiURL := fmt.Sprintf("%s/v7/indices/3d?type=7&location=%s,%s&lang=%s", config.QweatherUrl, longitude, latitude, language)
+	if err := fetchAPI(ctx, "/v7/indices/3d", apiURL, &response); err != nil {
+		return nil, err
+	}
+
+	days := make([]models.DailyPollenResult, 0, len(response.Daily))
+	for _, day := range response.Daily {
+		// Allergy index levels run 1 (very unlikely) to 5 (very likely)
+		level := int(day.Level) - 1
+		if level < utils.PollenNone {
+			level = utils.PollenNone
+		}
+		if level > utils.PollenVeryHigh {
+			level = utils.PollenVeryHigh
+		}
+		days = append(days, models.DailyPollenResult{
+			Date:      day.Date,
+			Risk:      utils.PollenLevelName(level),
+			RiskLevel: level,
+			Text:      strings.TrimSpace(day.Category + " " + day.Text),
+		})
+	}
+	return days, nil
+}
+
+// GetAirQualityDetails returns current, hourly and daily air quality
+func GetAirQualityDetails(ctx context.Context, latitude, longitude, language string) (models.AirQualityResult, error) {
+	latFloat, _ := strconv.ParseFloat(latitude, 64)
+	lonFloat, _ := strconv.ParseFloat(longitude, 64)
+
+	// Cache geolocation within approximately 1.11 kilometer range
+	cacheKey := fmt.Sprintf("air:qweather:%.2f:%.2f:%s", latFloat, lonFloat, language)
+	var cached models.AirQualityResult
+	if cache.Get(ctx, "qweather", cacheKey, &cached) {
+		return cached, nil
+	}
+
+	var wg sync.WaitGroup
+	var currentData models.CurrentAirQualityResult
+	var hourlyData []models.HourlyAirQualityResult
+	var dailyData []models.DailyAirQualityResult
+	var pollenData []models.DailyPollenResult
+
+	errChan := make(chan error, 3)
+
+	wg.Add(4)
+	go func() {
+		defer wg.Done()
+		var err error
+		currentData, err = fetchCurrentAirQualityData(ctx, latitude, longitude, language)
+		errChan <- err
+	}()
+	go func() {
+		defer wg.Done()
+		var err error
+		hourlyData, err = fetchHourlyAirQualityData(ctx, latitude, longitude, language)
+		errChan <- err
+	}()
+	go func() {
+		defer wg.Done()
+		var err error
+		dailyData, err = fetchDailyAirQualityData(ctx, latitude, longitude, language)
+		errChan <- err
+	}()
+	go func() {
+		defer wg.Done()
+		// Pollen is optional, outside China the index is simply unavailable
+		var err error
+		if pollenData, err = fetchPollenData(ctx, latitude, longitude, language); err != nil {
+			logging.FromContext(ctx).Info("Pollen data unavailable", zap.String("provider", "qweather"), zap.Error(err))
+		}
+	}()
+	wg.Wait()
+	close(errChan)
+
+	for err := range errChan {
+		if err != nil {
+			return models.AirQualityResult{}, fmt.Errorf("error fetching QWeather air quality data: %w", err)
+		}
+	}
+
+	airQualityResult := models.AirQualityResult{
+		Current: currentData,
+		Hourly:  hourlyData,
+		Daily:   dailyData,
+		Pollen:  pollenData,
+	}
+
+	cache.Set(ctx, "qweather", cacheKey, airQualityResult, config.CacheTTL)
+
+	return airQualityResult, nil
+}
+
+// toProviderIndex picks the first (local) index QWeather returns for a location
+func toProviderIndex(indexes []qAirQualityIndex) (models.ProviderAQI, string) {
+	if len(indexes) == 0 {
+		return models.ProviderAQI{}, ""
+	}
+	index := indexes[0]
+	primary := ""
+	if index.PrimaryPollutant != nil {
+		primary = toPollutantName(index.PrimaryPollutant.Code)
+	}
+	return models.ProviderAQI{
+		Scale:    index.Code,
+		Value:    float64(index.Aqi),
+		Category: index.Category,
+	}, primary
+}
+
+// toPollutants normalizes v1 pollutant entries to µg/m³. Entries in units
+// that cannot be converted are logged and left out.
+func toPollutants(ctx context.Context, pollutants []qAirQualityPollutant) models.Pollutants {
+	var result models.Pollutants
+	for _, p := range pollutants {
+		name := toPollutantName(p.Code)
+		value, err := toMicrogramsPerCubicMeter(float64(p.Concentration.Value), p.Concentration.Unit, name)
+		if err != nil {
+			logging.FromContext(ctx).Warn("Skipping pollutant", zap.String("provider", "qweather"),
+				zap.String("pollutant", p.Code), zap.Error(err))
+			continue
+		}
+		switch name {
+		case "pm2_5":
+			result.Pm25 = value
+		case "pm10":
+			result.Pm10 = value
+		case "ozone":
+			result.Ozone = value
+		case "nitrogen_dioxide":
+			result.NitrogenDioxide = value
+		case "sulfur_dioxide":
+			result.SulfurDioxide = value
+		case "carbon_monoxide":
+			result.CarbonMonoxide = value
+		}
+	}
+	return result
+}
+
+// Molecular weights in g/mol of the gases QWeather may report by volume
+var molecularWeights = map[string]float64{
+	"ozone":            48.00,
+	"nitrogen_dioxide": 46.01,
+	"sulfur_dioxide":   64.07,
+	"carbon_monoxide":  28.01,
+}
+
+// Molar volume in litres at 25 °C and 1 atm, the reference for ppb to µg/m³
+const molarVolume = 24.45
+
+// toMicrogramsPerCubicMeter converts mass concentrations in mg/m³ and gas
+// mixing ratios in ppb or ppm, as the v1 API reports O3, NO2, SO2 and CO
+func toMicrogramsPerCubicMeter(value float64, unit, pollutant string) (float64, error) {
+	normalized := strings.NewReplacer("µ", "u", "μ", "u", "³", "3").Replace(strings.ToLower(strings.TrimSpace(unit)))
+	switch normalized {
+	case "ug/m3":
+		return value, nil
+	case "mg/m3":
+		return value * 1000, nil
+	case "ppb", "ppm":
+		weight, ok := molecularWeights[pollutant]
+		if !ok {
+			return 0, fmt.Errorf("cannot convert %s of %q to µg/m³", unit, pollutant)
+		}
+		if normalized == "ppm" {
+			value *= 1000
+		}
+		return value * weight / molarVolume, nil
+	}
+	return 0, fmt.Errorf("unknown concentration unit %q", unit)
+}
+
+// toPollutantName maps QWeather pollutant codes ("pm2p5", "PM2.5", "o3") to API field names
+func toPollutantName(code string) string {
+	switch strings.ToLower(strings.ReplaceAll(code, ".", "p")) {
+	case "pm2p5":
+		return "pm2_5"
+	case "pm10":
+		return "pm10"
+	case "o3":
+		return "ozone"
+	case "no2":
+		return "nitrogen_dioxide"
+	case "so2":
+		return "sulfur_dioxide"
+	case "co":
+		return "carbon_monoxide"
+	}
+	return ""
+}
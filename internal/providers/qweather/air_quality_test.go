@@ -0,0 +1,67 @@
+package qweather
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestToMicrogramsPerCubicMeter(t *testing.T) {
+	cases := []struct {
+		name      string
+		value     float64
+		unit      string
+		pollutant string
+		want      float64
+		wantErr   bool
+	}{
+		{"micrograms", 35, "μg/m3", "pm2_5", 35, false},
+		{"micrograms with micro sign", 35, "µg/m³", "pm2_5", 35, false},
+		{"milligrams", 0.8, "mg/m3", "carbon_monoxide", 800, false},
+		{"ozone ppb", 40, "ppb", "ozone", 40 * 48.00 / 24.45, false},
+		{"nitrogen dioxide ppb", 20, "ppb", "nitrogen_dioxide", 20 * 46.01 / 24.45, false},
+		{"sulfur dioxide ppb", 5, "PPB", "sulfur_dioxide", 5 * 64.07 / 24.45, false},
+		{"carbon monoxide ppm", 0.5, "ppm", "carbon_monoxide", 500 * 28.01 / 24.45, false},
+		{"particles by volume", 10, "ppb", "pm10", 0, true},
+		{"unknown unit", 10, "grains/ft3", "pm10", 0, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := toMicrogramsPerCubicMeter(c.value, c.unit, c.pollutant)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, c.wantErr)
+			}
+			if math.Abs(got-c.want) > 1e-9 {
+				t.Errorf("toMicrogramsPerCubicMeter(%v, %q) = %v, want %v", c.value, c.unit, got, c.want)
+			}
+		})
+	}
+}
+
+func TestToPollutantsSkipsUnknownUnits(t *testing.T) {
+	var pollutants []qAirQualityPollutant
+	err := json.Unmarshal([]byte(`[
+		{"code":"pm2p5","concentration":{"value":"12","unit":"μg/m3"}},
+		{"code":"o3","concentration":{"value":"40","unit":"ppb"}},
+		{"code":"co","concentration":{"value":"0.5","unit":"ppm"}},
+		{"code":"no2","concentration":{"value":"9","unit":"furlongs"}}
+	]`), &pollutants)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := toPollutants(context.Background(), pollutants)
+	if result.Pm25 != 12 {
+		t.Errorf("Pm25 = %v, want 12", result.Pm25)
+	}
+	if want := 40 * 48.00 / 24.45; math.Abs(result.Ozone-want) > 1e-9 {
+		t.Errorf("Ozone = %v, want %v", result.Ozone, want)
+	}
+	if want := 500 * 28.01 / 24.45; math.Abs(result.CarbonMonoxide-want) > 1e-9 {
+		t.Errorf("CarbonMonoxide = %v, want %v", result.CarbonMonoxide, want)
+	}
+	if result.NitrogenDioxide != 0 {
+		t.Errorf("NitrogenDioxide = %v, want 0 for an unknown unit", result.NitrogenDioxide)
+	}
+}
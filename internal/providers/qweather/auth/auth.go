@@ -2,30 +2,162 @@ package auth
 
 import (
 	"Zephyr/internal/config"
+	"Zephyr/internal/metrics"
+	"Zephyr/internal/models"
 	"crypto/ed25519"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// Generate Ed25519 JWT
-func GenerateJWT() (string, error) {
-	priv, err := ParseEd25519PrivateKeyFromPEM([]byte(config.QweatherConfig.PrivateKeyPem))
+const (
+	// QWeather accepts tokens valid for at most 24 hours; we use 30 minutes
+	tokenLifetime = 1800 * time.Second
+	// Sign a new token this long before the cached one expires
+	refreshMargin = 5 * time.Minute
+)
+
+// TokenManager signs QWeather JWTs with the active key and reuses each token
+// until shortly before it expires
+type TokenManager struct {
+	mu        sync.Mutex
+	projectID string
+	keyID     string
+	keys      map[string]ed25519.PrivateKey
+	token     string
+	expiresAt time.Time
+}
+
+var manager = &TokenManager{}
+
+// Reload replaces the keys of the shared manager. On error the previous
+// keys stay in use.
+func Reload(cfg models.QweatherConfig) error {
+	return manager.Reload(cfg)
+}
+
+// Token returns a valid token from the shared manager. Keys are loaded from
+// the startup configuration on first use.
+func Token() (string, error) {
+	manager.mu.Lock()
+	loaded := manager.keys != nil
+	manager.mu.Unlock()
+
+	if !loaded {
+		if err := manager.Reload(config.QweatherConfig); err != nil {
+			metrics.JWTFailures.Inc()
+			return "", err
+		}
+	}
+	token, err := manager.Token()
 	if err != nil {
-		return "", err
+		metrics.JWTFailures.Inc()
+	}
+	return token, err
+}
+
+// Reload parses all configured keys and checks that the active key ID is
+// among them before switching over
+func (m *TokenManager) Reload(cfg models.QweatherConfig) error {
+	keys, err := loadKeys(cfg)
+	if err != nil {
+		return err
+	}
+	if _, found := keys[cfg.KeyID]; !found {
+		return fmt.Errorf("no private key configured for QWeather key ID %q", cfg.KeyID)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.projectID = cfg.ProjectID
+	m.keyID = cfg.KeyID
+	m.keys = keys
+	// Tokens signed with the old configuration are no longer reused
+	m.token = ""
+	m.expiresAt = time.Time{}
+	return nil
+}
+
+// Token returns the cached token or signs a new one
+func (m *TokenManager) Token() (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if m.token != "" && now.Before(m.expiresAt.Add(-refreshMargin)) {
+		return m.token, nil
 	}
-	now := time.Now().Unix()
+
+	priv, found := m.keys[m.keyID]
+	if !found {
+		return "", errors.New("QWeather private key not loaded")
+	}
+
+	expiresAt := now.Add(tokenLifetime)
 	claims := jwt.MapClaims{
-		"sub": config.QweatherConfig.ProjectID,
-		"iat": now - 30,
-		"exp": now + 1800,
+		"sub": m.projectID,
+		// Allow for clock differences with QWeather
+		"iat": now.Unix() - 30,
+		"exp": expiresAt.Unix(),
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
-	token.Header["kid"] = config.QweatherConfig.KeyID
-	return token.SignedString(priv)
+	token.Header["kid"] = m.keyID
+	signed, err := token.SignedString(priv)
+	if err != nil {
+		return "", err
+	}
+
+	m.token, m.expiresAt = signed, expiresAt
+	return signed, nil
+}
+
+// loadKeys reads the active key from its file or PEM value and any
+// additional key files
+func loadKeys(cfg models.QweatherConfig) (map[string]ed25519.PrivateKey, error) {
+	keys := make(map[string]ed25519.PrivateKey)
+
+	for _, entry := range cfg.KeyFiles {
+		kid, path, found := strings.Cut(entry, ":")
+		if !found || kid == "" || path == "" {
+			return nil, fmt.Errorf("invalid QWeather key file entry %q, expected kid:path", entry)
+		}
+		priv, err := readKeyFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("QWeather key %s: %w", kid, err)
+		}
+		keys[kid] = priv
+	}
+
+	pemBytes := []byte(cfg.PrivateKeyPem)
+	if cfg.PrivateKeyFile != "" {
+		var err error
+		if pemBytes, err = os.ReadFile(cfg.PrivateKeyFile); err != nil {
+			return nil, fmt.Errorf("QWeather key %s: %w", cfg.KeyID, err)
+		}
+	}
+	if len(pemBytes) > 0 {
+		priv, err := ParseEd25519PrivateKeyFromPEM(pemBytes)
+		if err != nil {
+			return nil, fmt.Errorf("QWeather key %s: %w", cfg.KeyID, err)
+		}
+		keys[cfg.KeyID] = priv
+	}
+	return keys, nil
+}
+
+func readKeyFile(path string) (ed25519.PrivateKey, error) {
+	pemBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseEd25519PrivateKeyFromPEM(pemBytes)
 }
 
 // Parse Ed25519 PEM private key
@@ -1,18 +1,23 @@
 package qweather
 
 import (
+	"Zephyr/internal/cache"
 	"Zephyr/internal/config"
+	"Zephyr/internal/logging"
 	"Zephyr/internal/models"
 	"Zephyr/internal/providers/qweather/auth"
+	"Zephyr/internal/upstream"
 	"Zephyr/pkg/utils"
 	"compress/gzip"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"strconv"
 	"sync"
+
+	"go.uber.org/zap"
 )
 
 type StringFloat64 float64
@@ -55,8 +60,10 @@ func (si *StringInt) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-func fetchAPI(apiURL string, target interface{}) error {
-	token, err := auth.GenerateJWT()
+// fetchAPI requests a QWeather API. The endpoint is the path template used
+// to label metrics and spans, e.g. "/airquality/v1/hourly/{lat}/{lon}".
+func fetchAPI(ctx context.Context, endpoint, apiURL string, target interface{}) error {
+	token, err := auth.Token()
 	if err != nil {
 		return fmt.Errorf("failed to generate JWT: %w", err)
 	}
@@ -68,7 +75,7 @@ func fetchAPI(apiURL string, target interface{}) error {
 	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("Accept-Encoding", "gzip")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := upstream.Do(ctx, "qweather", endpoint, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute HTTP request: %w", err)
 	}
@@ -88,7 +95,7 @@ func fetchAPI(apiURL string, target interface{}) error {
 	if err != nil {
 		return fmt.Errorf("failed to read response body: %w", err)
 	}
-	log.Printf("QWeather API Response for %s: %s", apiURL, string(body))
+	logging.UpstreamPayload(ctx, "qweather", apiURL, body)
 
 	if err := json.Unmarshal(body, target); err != nil {
 		return fmt.Errorf("failed to unmarshal response: %w", err)
@@ -97,7 +104,7 @@ func fetchAPI(apiURL string, target interface{}) error {
 	return nil
 }
 
-func fetchNowWeatherData(latitude, longitude, language, unit string) (models.CurrentWeatherResult, error) {
+func fetchNowWeatherData(ctx context.Context, latitude, longitude, language, unit string) (models.CurrentWeatherResult, error) {
 	type qWeatherNowResponse struct {
 		Now struct {
 			Temp       StringFloat64 `json:"temp"`
@@ -113,7 +120,7 @@ func fetchNowWeatherData(latitude, longitude, language, unit string) (models.Cur
 
 	var response qWeatherNowResponse
 	apiURL := fmt.Sprintf("%s/v7/weather/now?location=%s,%s&lang=%s&unit=%s", config.QweatherUrl, longitude, latitude, language, unit)
-	if err := fetchAPI(apiURL, &response); err != nil {
+	if err := fetchAPI(ctx, "/v7/weather/now", apiURL, &response); err != nil {
 		return models.CurrentWeatherResult{}, err
 	}
 
@@ -132,7 +139,7 @@ func fetchNowWeatherData(latitude, longitude, language, unit string) (models.Cur
 	return currentWeather, nil
 }
 
-func fetchNowAirQualityData(latitude, longitude, language, unit string) (models.CurrentWeatherResult, error) {
+func fetchNowAirQualityData(ctx context.Context, latitude, longitude, language, unit string) (models.CurrentWeatherResult, error) {
 	type qAirQualityResponse struct {
 		Now struct {
 			Aqi      string `json:"aqi"`
@@ -149,7 +156,7 @@ func fetchNowAirQualityData(latitude, longitude, language, unit string) (models.
 
 	var response qAirQualityResponse
 	apiURL := fmt.Sprintf("%s/v7/air/now?location=%s,%s&lang=%s", config.QweatherUrl, longitude, latitude, language)
-	if err := fetchAPI(apiURL, &response); err != nil {
+	if err := fetchAPI(ctx, "/v7/air/now", apiURL, &response); err != nil {
 		return models.CurrentWeatherResult{}, err
 	}
 
@@ -176,7 +183,7 @@ func fetchNowAirQualityData(latitude, longitude, language, unit string) (models.
 	return airQuality, nil
 }
 
-func fetchDailyWeatherData(latitude, longitude, language, unit string) ([]models.DailyWeatherResult, error) {
+func fetchDailyWeatherData(ctx context.Context, latitude, longitude, language, unit string) ([]models.DailyWeatherResult, error) {
 	type qDailyResponse struct {
 		Daily []struct {
 			FxDate  string        `json:"fxDate"`
@@ -189,7 +196,7 @@ func fetchDailyWeatherData(latitude, longitude, language, unit string) ([]models
 
 	var response qDailyResponse
 	apiURL := fmt.Sprintf("%s/v7/weather/7d?location=%s,%s&lang=%s&unit=%s", config.QweatherUrl, longitude, latitude, language, unit)
-	if err := fetchAPI(apiURL, &response); err != nil {
+	if err := fetchAPI(ctx, "/v7/weather/7d", apiURL, &response); err != nil {
 		return nil, err
 	}
 
@@ -206,7 +213,7 @@ func fetchDailyWeatherData(latitude, longitude, language, unit string) ([]models
 	return dailyWeathers, nil
 }
 
-func fetchHourlyWeatherData(latitude, longitude, language, unit string) ([]models.HourlyWeatherResult, error) {
+func fetchHourlyWeatherData(ctx context.Context, latitude, longitude, language, unit string) ([]models.HourlyWeatherResult, error) {
 	type qHourlyResponse struct {
 		Hourly []struct {
 			FxTime    string        `json:"fxTime"`
@@ -220,7 +227,7 @@ func fetchHourlyWeatherData(latitude, longitude, language, unit string) ([]model
 
 	var response qHourlyResponse
 	apiURL := fmt.Sprintf("%s/v7/weather/24h?location=%s,%s&lang=%s&unit=%s", config.QweatherUrl, longitude, latitude, language, unit)
-	if err := fetchAPI(apiURL, &response); err != nil {
+	if err := fetchAPI(ctx, "/v7/weather/24h", apiURL, &response); err != nil {
 		return nil, err
 	}
 
@@ -238,7 +245,7 @@ func fetchHourlyWeatherData(latitude, longitude, language, unit string) ([]model
 	return hourlyWeathers, nil
 }
 
-func GetAllForecastDetails(latitude, longitude, language, unit string) models.WeatherResult {
+func GetAllForecastDetails(ctx context.Context, latitude, longitude, language, unit string) models.WeatherResult {
 	// Convert to float64 type
 	latFloat, _ := strconv.ParseFloat(latitude, 64)
 	lonFloat, _ := strconv.ParseFloat(longitude, 64)
@@ -247,12 +254,9 @@ func GetAllForecastDetails(latitude, longitude, language, unit string) models.We
 	cacheLatitude := fmt.Sprintf("%.2f", latFloat)
 	cacheLongitude := fmt.Sprintf("%.2f", lonFloat)
 	cacheKey := fmt.Sprintf("weather:qweather:%s:%s:%s:%s", cacheLatitude, cacheLongitude, language, unit)
-	if cachedData, err := config.RedisClient.Get(config.Ctx, cacheKey).Result(); err == nil {
-		var weatherResult models.WeatherResult
-		if err := json.Unmarshal([]byte(cachedData), &weatherResult); err == nil {
-			log.Printf("Retrieved weather data from cache: %s\n", cacheKey)
-			return weatherResult
-		}
+	var cached models.WeatherResult
+	if cache.Get(ctx, "qweather", cacheKey, &cached) {
+		return cached
 	}
 
 	var wg sync.WaitGroup
@@ -267,25 +271,25 @@ func GetAllForecastDetails(latitude, longitude, language, unit string) models.We
 	go func() {
 		defer wg.Done()
 		var err error
-		currentWeatherData, err = fetchNowWeatherData(latitude, longitude, language, unit)
+		currentWeatherData, err = fetchNowWeatherData(ctx, latitude, longitude, language, unit)
 		errChan <- err
 	}()
 	go func() {
 		defer wg.Done()
 		var err error
-		airQualityData, err = fetchNowAirQualityData(latitude, longitude, language, unit)
+		airQualityData, err = fetchNowAirQualityData(ctx, latitude, longitude, language, unit)
 		errChan <- err
 	}()
 	go func() {
 		defer wg.Done()
 		var err error
-		dailyWeatherData, err = fetchDailyWeatherData(latitude, longitude, language, unit)
+		dailyWeatherData, err = fetchDailyWeatherData(ctx, latitude, longitude, language, unit)
 		errChan <- err
 	}()
 	go func() {
 		defer wg.Done()
 		var err error
-		hourlyWeatherData, err = fetchHourlyWeatherData(latitude, longitude, language, unit)
+		hourlyWeatherData, err = fetchHourlyWeatherData(ctx, latitude, longitude, language, unit)
 		errChan <- err
 	}()
 	wg.Wait()
@@ -293,7 +297,7 @@ func GetAllForecastDetails(latitude, longitude, language, unit string) models.We
 
 	for err := range errChan {
 		if err != nil {
-			log.Printf("Error fetching QWeather data: %v", err)
+			logging.FromContext(ctx).Warn("Failed to fetch forecast data", zap.String("provider", "qweather"), zap.Error(err))
 			return models.WeatherResult{}
 		}
 	}
@@ -312,10 +316,7 @@ func GetAllForecastDetails(latitude, longitude, language, unit string) models.We
 		HWR: hourlyWeatherData,
 	}
 
-	if cachedData, err := json.Marshal(weatherResult); err == nil {
-		log.Printf("Cached weather data: %s\n", cacheKey)
-		config.RedisClient.Set(config.Ctx, cacheKey, cachedData, config.CacheTTL)
-	}
+	cache.Set(ctx, "qweather", cacheKey, weatherResult, config.CacheTTL)
 
 	return weatherResult
 }
@@ -0,0 +1,83 @@
+package qweather
+
+import (
+	"Zephyr/internal/cache"
+	"Zephyr/internal/config"
+	"Zephyr/internal/models"
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// indexTypes maps QWeather index type IDs to stable API keys
+var indexTypes = map[string]string{
+	"1":  "sports",
+	"2":  "car_wash",
+	"3":  "dressing",
+	"4":  "fishing",
+	"5":  "uv",
+	"6":  "travel",
+	"7":  "allergy",
+	"8":  "comfort",
+	"9":  "flu",
+	"10": "air_pollution_diffusion",
+	"11": "air_conditioning",
+	"12": "sunglasses",
+	"13": "makeup",
+	"14": "drying",
+	"15": "traffic",
+	"16": "sunscreen",
+}
+
+// GetLifestyleIndices returns today's lifestyle indices. QWeather only
+// covers some regions, so an empty result is not an error.
+func GetLifestyleIndices(ctx context.Context, latitude, longitude, language string) ([]models.LifestyleIndex, error) {
+	latFloat, _ := strconv.ParseFloat(latitude, 64)
+	lonFloat, _ := strconv.ParseFloat(longitude, 64)
+
+	// Cache geolocation within approximately 1.11 kilometer range
+	cacheKey := fmt.Sprintf("indices:qweather:%.2f:%.2f:%s", latFloat, lonFloat, language)
+	var cached []models.LifestyleIndex
+	if cache.Get(ctx, "qweather", cacheKey, &cached) {
+		return cached, nil
+	}
+
+	type qIndicesResponse struct {
+		Daily []struct {
+			Date     string    `json:"date"`
+			Type     string    `json:"type"`
+			Name     string    `json:"name"`
+			Level    StringInt `json:"level"`
+			Category string    `json:"category"`
+			Text     string    `json:"text"`
+		} `json:"daily"`
+	}
+
+	var response qIndicesResponse
+	apiURL := fmt.Sprintf("%s/v7/indices/1d?type=0&location=%s,%s&lang=%s", config.QweatherUrl, longitude, latitude, language)
+	if err := fetchAPI(ctx, "/v7/indices/1d", apiURL, &response); err != nil {
+		return nil, err
+	}
+
+	indices := make([]models.LifestyleIndex, 0, len(response.Daily))
+	for _, index := range response.Daily {
+		indexType, ok := indexTypes[index.Type]
+		if !ok {
+			indexType = "qweather_" + index.Type
+		}
+		indices = append(indices, models.LifestyleIndex{
+			Date:     index.Date,
+			Type:     indexType,
+			Name:     index.Name,
+			Level:    int(index.Level),
+			Category: index.Category,
+			Text:     index.Text,
+		})
+	}
+
+	if len(indices) > 0 {
+		cache.Set(ctx, "qweather", cacheKey, indices, config.CacheTTL)
+	}
+
+	return indices, nil
+}
@@ -0,0 +1,42 @@
+package qweather
+
+import (
+	"Zephyr/internal/config"
+	"Zephyr/internal/models"
+	"context"
+	"net/url"
+)
+
+// LookupLocation resolves a QWeather location ID. The boolean is false when
+// QWeather does not know the ID.
+func LookupLocation(ctx context.Context, id, language string) (models.Location, bool, error) {
+	var response struct {
+		Code     string `json:"code"`
+		Location []struct {
+			ID      string        `json:"id"`
+			Name    string        `json:"name"`
+			Lat     StringFloat64 `json:"lat"`
+			Lon     StringFloat64 `json:"lon"`
+			Adm1    string        `json:"adm1"`
+			Country string        `json:"country"`
+		} `json:"location"`
+	}
+
+	apiURL := config.QweatherUrl + "/geo/v2/city/lookup?location=" + url.QueryEscape(id) + "&lang=" + language
+	if err := fetchAPI(ctx, "/geo/v2/city/lookup", apiURL, &response); err != nil {
+		return models.Location{}, false, err
+	}
+	if len(response.Location) == 0 {
+		return models.Location{}, false, nil
+	}
+
+	loc := response.Location[0]
+	return models.Location{
+		ID:        "qw:" + loc.ID,
+		Name:      loc.Name,
+		Latitude:  float64(loc.Lat),
+		Longitude: float64(loc.Lon),
+		State:     loc.Adm1,
+		Country:   loc.Country,
+	}, true, nil
+}
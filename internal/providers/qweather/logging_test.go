@@ -0,0 +1,87 @@
+package qweather
+
+import (
+	"Zephyr/internal/config"
+	"Zephyr/internal/logging"
+	"Zephyr/internal/models"
+	"Zephyr/internal/providers/qweather/auth"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// TestUpstreamCallsDoNotLogCredentials fails if a QWeather token reaches the
+// logs, even when the upstream echoes it back in the response body
+func TestUpstreamCallsDoNotLogCredentials(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemKey := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if err := auth.Reload(models.QweatherConfig{KeyID: "test", ProjectID: "project", PrivateKeyPem: pemKey}); err != nil {
+		t.Fatal(err)
+	}
+	token, err := auth.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"code":"200","location":[{"id":"101010100","name":"Beijing"}],"debug":{"authorization":%q}}`,
+			r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	// Log everything, including every upstream payload, and put the shared
+	// logger and settings back for the tests that follow
+	previousLogger := logging.L()
+	previousURL := config.QweatherUrl
+	previousSample, previousMax := config.UpstreamLogSamplePercent, config.UpstreamLogMaxBytes
+	t.Cleanup(func() {
+		logging.SetLogger(previousLogger)
+		config.QweatherUrl = previousURL
+		config.UpstreamLogSamplePercent, config.UpstreamLogMaxBytes = previousSample, previousMax
+	})
+	var output bytes.Buffer
+	logging.SetLogger(logging.New(zapcore.AddSync(&output), zapcore.DebugLevel))
+	config.QweatherUrl = server.URL
+	config.UpstreamLogSamplePercent = 100
+	config.UpstreamLogMaxBytes = 4096
+
+	ctx := context.Background()
+	if _, err := SearchCitiesFromQw(ctx, "beijing", "en"); err != nil {
+		t.Fatal(err)
+	}
+	var target map[string]any
+	if err := fetchAPI(ctx, "/v7/weather/now", server.URL+"/v7/weather/now?location=116.41,39.92", &target); err != nil {
+		t.Fatal(err)
+	}
+	log.Printf("request failed with Authorization: Bearer %s", token)
+
+	logs := output.String()
+	if !strings.Contains(logs, "Upstream response") {
+		t.Fatalf("expected upstream payloads to be logged at debug level, got:\n%s", logs)
+	}
+	if strings.Contains(logs, token) {
+		t.Fatalf("QWeather token leaked into logs:\n%s", logs)
+	}
+	for _, secret := range []string{"BEGIN PRIVATE KEY", "Bearer ey"} {
+		if strings.Contains(logs, secret) {
+			t.Fatalf("%q leaked into logs:\n%s", secret, logs)
+		}
+	}
+}
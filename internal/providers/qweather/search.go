@@ -2,17 +2,19 @@ package qweather
 
 import (
 	"Zephyr/internal/config"
+	"Zephyr/internal/logging"
 	"Zephyr/internal/models"
 	"Zephyr/internal/providers/qweather/auth"
+	"Zephyr/internal/upstream"
 	"compress/gzip"
+	"context"
 	"encoding/json"
 	"io"
-	"log"
 	"net/http"
 )
 
-func SearchCitiesFromQw(location, lang string) ([]byte, error) {
-	token, err := auth.GenerateJWT()
+func SearchCitiesFromQw(ctx context.Context, location, lang string) ([]byte, error) {
+	token, err := auth.Token()
 	if err != nil {
 		return nil, err
 	}
@@ -20,10 +22,9 @@ func SearchCitiesFromQw(location, lang string) ([]byte, error) {
 	apiURL := config.QweatherUrl + "/geo/v2/city/lookup?location=" + location + "&lang=" + lang
 	req, _ := http.NewRequest("GET", apiURL, nil)
 	req.Header.Set("Authorization", "Bearer "+token)
-	log.Println(token)
 	req.Header.Set("Accept-Encoding", "gzip")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := upstream.Do(ctx, "qweather", "/geo/v2/city/lookup", req)
 	if err != nil {
 		return nil, err
 	}
@@ -42,10 +43,11 @@ func SearchCitiesFromQw(location, lang string) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	log.Println("QWeather API Response:", string(body))
+	logging.UpstreamPayload(ctx, "qweather", apiURL, body)
 
 	var qweatherResponse struct {
 		Location []struct {
+			ID      string `json:"id"`
 			Name    string `json:"name"`
 			Lat     string `json:"lat"`
 			Lon     string `json:"lon"`
@@ -60,9 +62,10 @@ func SearchCitiesFromQw(location, lang string) ([]byte, error) {
 	var filteredResults []models.FilteredSearchResult
 	for _, loc := range qweatherResponse.Location {
 		filteredResult := models.FilteredSearchResult{
-			Name: loc.Name,
-			Lat:  loc.Lat,
-			Lon:  loc.Lon,
+			Name:       loc.Name,
+			Lat:        loc.Lat,
+			Lon:        loc.Lon,
+			LocationID: "qw:" + loc.ID,
 			Address: struct {
 				State   string `json:"state"`
 				Country string `json:"country"`
@@ -0,0 +1,152 @@
+package qweather
+
+import (
+	"Zephyr/internal/cache"
+	"Zephyr/internal/config"
+	"Zephyr/internal/models"
+	"Zephyr/pkg/utils"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// Search radius for tide stations in kilometers (QWeather allows up to 50)
+	tideStationRadiusKm = 50
+	// Tide stations do not move, cache the nearest one for a week
+	tideStationCacheTTL = 7 * 24 * time.Hour
+	// Predictions for a station and day never change
+	tideCacheTTL = 24 * time.Hour
+)
+
+// ErrNoTideStation is returned when no tide station is within range
+var ErrNoTideStation = errors.New("no tide station found near this location")
+
+// FindNearestTideStation looks up tide stations (POI type TSTA) around the
+// coordinates and returns the closest one
+func FindNearestTideStation(ctx context.Context, latitude, longitude, language string) (models.TideStation, error) {
+	latFloat, _ := strconv.ParseFloat(latitude, 64)
+	lonFloat, _ := strconv.ParseFloat(longitude, 64)
+
+	cacheKey := fmt.Sprintf("tide_station:qweather:%.2f:%.2f:%s", latFloat, lonFloat, language)
+	var cached models.TideStation
+	if cache.Get(ctx, "qweather", cacheKey, &cached) {
+		return cached, nil
+	}
+
+	type qPoiResponse struct {
+		Code string `json:"code"`
+		Poi  []struct {
+			ID   string        `json:"id"`
+			Name string        `json:"name"`
+			Lat  StringFloat64 `json:"lat"`
+			Lon  StringFloat64 `json:"lon"`
+			Tz   string        `json:"tz"`
+		} `json:"poi"`
+	}
+
+	var response qPoiResponse
+	apiURL := fmt.Sprintf("%s/geo/v2/poi/range?type=TSTA&location=%.2f,%.2f&radius=%d&number=20&lang=%s",
+		config.QweatherUrl, lonFloat, latFloat, tideStationRadiusKm, language)
+	if err := fetchAPI(ctx, "/geo/v2/poi/range", apiURL, &response); err != nil {
+		return models.TideStation{}, err
+	}
+	if len(response.Poi) == 0 {
+		return models.TideStation{}, ErrNoTideStation
+	}
+
+	var nearest models.TideStation
+	for i, poi := range response.Poi {
+		distance := utils.DistanceKm(latFloat, lonFloat, float64(poi.Lat), float64(poi.Lon))
+		if i == 0 || distance < nearest.DistanceKm {
+			nearest = models.TideStation{
+				ID:         poi.ID,
+				Name:       poi.Name,
+				Latitude:   float64(poi.Lat),
+				Longitude:  float64(poi.Lon),
+				DistanceKm: distance,
+				TimeZone:   poi.Tz,
+			}
+		}
+	}
+
+	cache.Set(ctx, "qweather", cacheKey, nearest, tideStationCacheTTL)
+	return nearest, nil
+}
+
+// StationDate returns today's date at the station as yyyyMMdd. Stations
+// without a known time zone, such as those given by ID, use UTC.
+func StationDate(station models.TideStation, now time.Time) string {
+	location := time.UTC
+	if station.TimeZone != "" {
+		if tz, err := time.LoadLocation(station.TimeZone); err == nil {
+			location = tz
+		}
+	}
+	return now.In(location).Format("20060102")
+}
+
+// GetTidePredictions returns high/low tides and hourly heights for a station
+// on a date formatted as yyyyMMdd
+func GetTidePredictions(ctx context.Context, station models.TideStation, date, language string) (models.TideResult, error) {
+	cacheKey := fmt.Sprintf("tide:qweather:%s:%s:%s", station.ID, date, language)
+	var cached models.TideResult
+	if cache.Get(ctx, "qweather", cacheKey, &cached) {
+		cached.Station = station
+		return cached, nil
+	}
+
+	type qTideResponse struct {
+		Code      string `json:"code"`
+		TideTable []struct {
+			FxTime string        `json:"fxTime"`
+			Height StringFloat64 `json:"height"`
+			Type   string        `json:"type"`
+		} `json:"tideTable"`
+		TideHourly []struct {
+			FxTime string        `json:"fxTime"`
+			Height StringFloat64 `json:"height"`
+		} `json:"tideHourly"`
+	}
+
+	var response qTideResponse
+	apiURL := fmt.Sprintf("%s/v7/ocean/tide?location=%s&date=%s&lang=%s", config.QweatherUrl,
+		url.QueryEscape(station.ID), url.QueryEscape(date), url.QueryEscape(language))
+	if err := fetchAPI(ctx, "/v7/ocean/tide", apiURL, &response); err != nil {
+		return models.TideResult{}, err
+	}
+	if response.Code != "200" {
+		return models.TideResult{}, fmt.Errorf("QWeather tide request failed with code %s", response.Code)
+	}
+
+	tideResult := models.TideResult{
+		Station:  station,
+		Date:     date,
+		Extremes: make([]models.TideExtreme, 0, len(response.TideTable)),
+		Hourly:   make([]models.TideHeight, 0, len(response.TideHourly)),
+	}
+	for _, extreme := range response.TideTable {
+		tideType := "low"
+		if extreme.Type == "H" {
+			tideType = "high"
+		}
+		tideResult.Extremes = append(tideResult.Extremes, models.TideExtreme{
+			Time:   extreme.FxTime,
+			Height: float64(extreme.Height),
+			Type:   tideType,
+		})
+	}
+	for _, hour := range response.TideHourly {
+		tideResult.Hourly = append(tideResult.Hourly, models.TideHeight{
+			Time:   hour.FxTime,
+			Height: float64(hour.Height),
+		})
+	}
+
+	cache.Set(ctx, "qweather", cacheKey, tideResult, tideCacheTTL)
+
+	return tideResult, nil
+}
@@ -1,19 +1,15 @@
 package qweather
 
 import (
+	"Zephyr/internal/alerts"
+	"Zephyr/internal/cache"
 	"Zephyr/internal/config"
 	"Zephyr/internal/models"
-	"Zephyr/internal/providers/qweather/auth"
-	"compress/gzip"
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
-	"log"
-	"net/http"
 	"strconv"
 	"strings"
-
-	"github.com/gin-gonic/gin"
+	"time"
 )
 
 func formatLocation(location string) string {
@@ -29,75 +25,84 @@ func formatLocation(location string) string {
 	return fmt.Sprintf("%.2f,%.2f", lon, lat)
 }
 
-func WeatherWarningFromQweather(c *gin.Context) {
-	location := c.Query("location")
+// WeatherWarningFromQweather fetches active warnings for a "lon,lat" location
+func WeatherWarningFromQweather(ctx context.Context, location, lang string) (models.QWeatherWarningResponse, error) {
 	location = formatLocation(location)
-	lang := c.DefaultQuery("lang", "zh")
-
-	if location == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "location parameter is required"})
-		return
-	}
 
 	cacheKey := fmt.Sprintf("qweather:warning:%s:%s", location, lang)
 	// 1. Check cache first
-	if val, err := config.RedisClient.Get(config.Ctx, cacheKey).Result(); err == nil {
-		var resp models.QWeatherWarningResponse
-		if err := json.Unmarshal([]byte(val), &resp); err == nil {
-			log.Printf("Retrieved warning data from cache: %s\n", cacheKey)
-			c.JSON(http.StatusOK, resp)
-			return
-		}
+	var cached models.QWeatherWarningResponse
+	if cache.Get(ctx, "qweather", cacheKey, &cached) {
+		return cached, nil
 	}
 
-	// 2. Generate JWT
-	token, err := auth.GenerateJWT()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "JWT generation failed"})
-		return
+	// 2. Request QWeather
+	var warningResp models.QWeatherWarningResponse
+	apiURL := fmt.Sprintf("%s%s?location=%s&lang=%s", config.QweatherUrl, "/v7/warning/now", location, lang)
+	if err := fetchAPI(ctx, "/v7/warning/now", apiURL, &warningResp); err != nil {
+		return models.QWeatherWarningResponse{}, err
 	}
 
-	// 3. Request QWeather
-	apiURL := fmt.Sprintf("%s%s?location=%s&lang=%s", config.QweatherUrl, "/v7/warning/now", location, lang)
-	req, _ := http.NewRequest("GET", apiURL, nil)
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Accept-Encoding", "gzip")
+	// 3. Cache the serialized JSON of the struct
+	cache.Set(ctx, "qweather", cacheKey, warningResp, config.CacheTTL)
+
+	return warningResp, nil
+}
+
+// AlertSource serves QWeather warnings as normalized alerts
+type AlertSource struct{}
 
-	resp, err := http.DefaultClient.Do(req)
+func (AlertSource) Name() string {
+	return "qweather"
+}
+
+func (s AlertSource) Fetch(ctx context.Context, latitude, longitude float64, language string) ([]models.Alert, error) {
+	location := fmt.Sprintf("%.2f,%.2f", longitude, latitude)
+	warningResp, err := WeatherWarningFromQweather(ctx, location, language)
 	if err != nil {
-		c.JSON(http.StatusBadGateway, gin.H{"error": "QWeather request failed"})
-		return
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	// Check if gzip compressed
-	bodyReader := resp.Body
-	if resp.Header.Get("Content-Encoding") == "gzip" {
-		gz, err := gzip.NewReader(resp.Body)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gzip decompression failed"})
-			return
-		}
-		defer gz.Close()
-		bodyReader = gz
-	}
-	body, err := io.ReadAll(bodyReader)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read QWeather response"})
-		return
+	alertList := make([]models.Alert, 0, len(warningResp.Warning))
+	for _, warning := range warningResp.Warning {
+		alertList = append(alertList, ToAlert(warning, language))
 	}
+	return alertList, nil
+}
 
-	// 4. Parse into struct
-	var warningResp models.QWeatherWarningResponse
-	if err := json.Unmarshal(body, &warningResp); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse QWeather response", "body": string(body)})
-		return
+// ToAlert maps a QWeather warning onto the provider-neutral alert model
+func ToAlert(warning models.QWeatherWarning, language string) models.Alert {
+	severity := alerts.ParseSeverity(warning.Severity)
+
+	// severityColor replaced the deprecated level field, which held the color name
+	color := alerts.ParseColor(warning.SeverityColor)
+	if color == models.ColorUnknown {
+		color = alerts.ParseColor(warning.Level)
+	}
+	if color == models.ColorUnknown {
+		color = alerts.ColorForSeverity(severity)
 	}
 
-	// 5. Cache the serialized JSON of the struct
-	cacheBytes, _ := json.Marshal(warningResp)
-	config.RedisClient.Set(config.Ctx, cacheKey, cacheBytes, config.CacheTTL)
+	var sent time.Time
+	if pubTime := alerts.ParseTime(warning.PubTime); pubTime != nil {
+		sent = *pubTime
+	}
 
-	// 6. Return struct
-	c.JSON(resp.StatusCode, warningResp)
+	return models.Alert{
+		ID:          warning.ID,
+		Source:      AlertSource{}.Name(),
+		Sender:      warning.Sender,
+		Event:       warning.TypeName,
+		Headline:    warning.Title,
+		Description: warning.Text,
+		Language:    language,
+		Severity:    severity,
+		Urgency:     alerts.ParseUrgency(warning.Urgency),
+		Certainty:   alerts.ParseCertainty(warning.Certainty),
+		Color:       color,
+		Status:      strings.ToLower(warning.Status),
+		Sent:        sent,
+		Onset:       alerts.ParseTime(warning.StartTime),
+		Expires:     alerts.ParseTime(warning.EndTime),
+	}
 }
@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"Zephyr/internal/config"
+	"Zephyr/pkg/utils"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// slidingWindowScript keeps one sorted set entry per accepted request, scored
+// by the caller's time in microseconds. Members are random so requests
+// arriving in the same instant are all counted. Running as a script makes
+// the check and the insert atomic across server instances. The time is
+// passed in rather than read with TIME, since Redis before 5.0 rejects
+// writes after non-deterministic commands in scripts.
+//
+// Returns {allowed, count, reset_us} where reset_us is the time until the
+// oldest entry leaves the window.
+var slidingWindowScript = redis.NewScript(`
+local now = tonumber(ARGV[4])
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[3])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', KEYS[1], math.ceil(window / 1000))
+
+local reset = window
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+return {allowed, count, reset}
+`)
+
+// now is replaced in tests
+var now = time.Now
+
+// Result describes the state of a window after a request
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Time until the oldest request leaves the window and frees a slot
+	Reset time.Duration
+}
+
+// Allow records a request under key if fewer than limit requests were
+// accepted within the trailing window
+func Allow(key string, limit int, window time.Duration) (Result, error) {
+	member, err := utils.RandomHex(8)
+	if err != nil {
+		return Result{}, err
+	}
+
+	values, err := slidingWindowScript.Run(config.Ctx, config.RedisClient, []string{key},
+		window.Microseconds(), limit, member, now().UnixMicro()).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	if len(values) != 3 {
+		return Result{}, fmt.Errorf("unexpected rate limit script result %v", values)
+	}
+
+	return Result{
+		Allowed:   values[0] == 1,
+		Limit:     limit,
+		Remaining: max(limit-int(values[1]), 0),
+		Reset:     time.Duration(values[2]) * time.Microsecond,
+	}, nil
+}
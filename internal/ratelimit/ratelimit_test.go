@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"Zephyr/internal/config"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+// useTestRedis points the shared client at an in-memory Redis for the test
+func useTestRedis(t *testing.T) *miniredis.Miniredis {
+	t.Helper()
+	server := miniredis.RunT(t)
+	previous := config.RedisClient
+	config.RedisClient = redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() {
+		config.RedisClient.Close()
+		config.RedisClient = previous
+	})
+	return server
+}
+
+// useClock fixes the time Allow passes to the script
+func useClock(t *testing.T, start time.Time) *time.Time {
+	t.Helper()
+	current := start
+	previous := now
+	now = func() time.Time { return current }
+	t.Cleanup(func() { now = previous })
+	return &current
+}
+
+func TestAllowWindowEdge(t *testing.T) {
+	useTestRedis(t)
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := useClock(t, start)
+	const limit, window = 2, time.Minute
+
+	steps := []struct {
+		at        time.Duration
+		allowed   bool
+		remaining int
+		reset     time.Duration
+	}{
+		{0, true, 1, window},
+		{10 * time.Second, true, 0, window - 10*time.Second},
+		{20 * time.Second, false, 0, window - 20*time.Second},
+		// The first request is still inside the window one microsecond early
+		{window - time.Microsecond, false, 0, time.Microsecond},
+		// and leaves it exactly one window later, freeing a single slot
+		{window, true, 0, 10 * time.Second},
+		{window + time.Second, false, 0, 9 * time.Second},
+		{window + 10*time.Second, true, 0, 50 * time.Second},
+	}
+	for _, step := range steps {
+		*clock = start.Add(step.at)
+		result, err := Allow("rate_limit:test", limit, window)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Allowed != step.allowed || result.Remaining != step.remaining || result.Reset != step.reset {
+			t.Errorf("at %v: Allow = %+v, want allowed %v, remaining %d, reset %v",
+				step.at, result, step.allowed, step.remaining, step.reset)
+		}
+		if result.Limit != limit {
+			t.Errorf("at %v: Limit = %d, want %d", step.at, result.Limit, limit)
+		}
+	}
+}
+
+func TestAllowSeparatesKeys(t *testing.T) {
+	useTestRedis(t)
+	useClock(t, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+
+	for _, key := range []string{"rate_limit:test:a", "rate_limit:test:b"} {
+		result, err := Allow(key, 1, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Allowed {
+			t.Errorf("Allow(%q) rejected the first request", key)
+		}
+	}
+}
+
+func TestAllowFailsWithoutRedis(t *testing.T) {
+	server := useTestRedis(t)
+	server.Close()
+
+	if _, err := Allow("rate_limit:test", 1, time.Minute); err == nil {
+		t.Error("Allow = nil error, want an error when Redis is down")
+	}
+}
@@ -0,0 +1,64 @@
+package signing
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	keys, err := ParseKeys([]string{"ios:first", "android:sec:ond"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(keys["ios"]) != "first" || string(keys["android"]) != "sec:ond" {
+		t.Errorf("ParseKeys = %q, want ios and android secrets", keys)
+	}
+
+	for _, entry := range []string{"s3cr3t-without-id", ":s3cr3t", "ios:"} {
+		_, err := ParseKeys([]string{entry})
+		if err == nil {
+			t.Errorf("ParseKeys(%q) = nil error, want an error", entry)
+		} else if strings.Contains(err.Error(), "s3cr3t") {
+			t.Errorf("ParseKeys(%q) error %q echoes the secret", entry, err)
+		}
+	}
+}
+
+func TestCanonicalRequestSortsQuery(t *testing.T) {
+	a := httptest.NewRequest("GET", "/api/v1/air?longitude=13.41&latitude=52.52", nil)
+	b := httptest.NewRequest("GET", "/api/v1/air?latitude=52.52&longitude=13.41", nil)
+	if CanonicalRequest(a, "ios", "1", "n", nil) != CanonicalRequest(b, "ios", "1", "n", nil) {
+		t.Error("CanonicalRequest depends on query parameter order")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	secret := []byte("secret")
+	r := httptest.NewRequest("POST", "/api/v1/devices?platform=ios", nil)
+	canonical := CanonicalRequest(r, "ios", "1700000000", "nonce-1", []byte(`{"token":"abc"}`))
+	signature := Sign(secret, canonical)
+
+	cases := []struct {
+		name      string
+		secret    []byte
+		canonical string
+		signature string
+		want      error
+	}{
+		{"valid", secret, canonical, signature, nil},
+		{"wrong secret", []byte("other"), canonical, signature, ErrInvalidSignature},
+		{"tampered body", secret, CanonicalRequest(r, "ios", "1700000000", "nonce-1", []byte(`{"token":"xyz"}`)), signature, ErrInvalidSignature},
+		{"tampered timestamp", secret, CanonicalRequest(r, "ios", "1700000001", "nonce-1", []byte(`{"token":"abc"}`)), signature, ErrInvalidSignature},
+		{"not hex", secret, canonical, "zz" + signature[2:], ErrInvalidSignature},
+		{"truncated", secret, canonical, signature[:32], ErrInvalidSignature},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := Verify(c.secret, c.canonical, c.signature); !errors.Is(err, c.want) {
+				t.Errorf("Verify = %v, want %v", err, c.want)
+			}
+		})
+	}
+}
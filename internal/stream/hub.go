@@ -0,0 +1,286 @@
+package stream
+
+import (
+	"Zephyr/internal/alerts"
+	"Zephyr/internal/cache"
+	"Zephyr/internal/config"
+	"Zephyr/internal/logging"
+	"Zephyr/internal/models"
+	"Zephyr/internal/providers/openmeteo"
+	"Zephyr/internal/providers/qweather"
+	"Zephyr/internal/subscriptions"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Event types pushed to stream subscribers
+const (
+	EventCurrent = "current"
+	EventAlerts  = "alerts"
+	EventNowcast = "nowcast"
+)
+
+// Resources lists the event types a subscriber can ask for
+var Resources = []string{EventCurrent, EventAlerts, EventNowcast}
+
+// Events buffered per subscriber before it is considered too slow
+const subscriberBuffer = 16
+
+// Event carries current conditions, a nowcast or a list of alert changes
+type Event struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// Location identifies what a feed watches. Coordinates are rounded to
+// ~1.11 km so nearby clients share one feed.
+type Location struct {
+	Latitude  float64
+	Longitude float64
+	Language  string
+	Source    string
+	Unit      string
+}
+
+func (l Location) key() string {
+	return fmt.Sprintf("%.2f:%.2f:%s:%s:%s", l.Latitude, l.Longitude, l.Language, l.Source, l.Unit)
+}
+
+// Hub runs one background refresher per watched location and fans its
+// updates out to every subscriber of that location
+type Hub struct {
+	sources func(source string) []alerts.Source
+
+	mu    sync.Mutex
+	feeds map[string]*feed
+}
+
+type feed struct {
+	location    Location
+	subscribers map[*Subscriber]struct{}
+	stop        chan struct{}
+	// Signals the refresher to poll now because a new resource was requested
+	wake chan struct{}
+
+	// Last known state, replayed to new subscribers
+	current *models.CurrentWeatherResult
+	alerts  map[string]models.Alert
+	nowcast *models.NowcastResult
+}
+
+// Subscriber receives events until it is closed. Events is closed when the
+// subscriber falls too far behind or is closed.
+type Subscriber struct {
+	Events    chan Event
+	hub       *Hub
+	feed      *feed
+	resources map[string]bool
+}
+
+func NewHub(sources func(source string) []alerts.Source) *Hub {
+	return &Hub{sources: sources, feeds: make(map[string]*feed)}
+}
+
+// Subscribe starts watching the given resources of a location, replaying
+// their last known state
+func (h *Hub) Subscribe(location Location, resources ...string) *Subscriber {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := location.key()
+	f, ok := h.feeds[key]
+	if !ok {
+		f = &feed{
+			location:    location,
+			subscribers: make(map[*Subscriber]struct{}),
+			stop:        make(chan struct{}),
+			wake:        make(chan struct{}, 1),
+		}
+		h.feeds[key] = f
+		go h.refresh(f)
+	}
+
+	subscriber := &Subscriber{
+		Events:    make(chan Event, subscriberBuffer),
+		hub:       h,
+		feed:      f,
+		resources: make(map[string]bool),
+	}
+	wanted := h.wantedLocked(f)
+	wake := false
+	for _, resource := range resources {
+		subscriber.resources[resource] = true
+		wake = wake || !wanted[resource]
+	}
+	f.subscribers[subscriber] = struct{}{}
+
+	// Existing feeds only poll what was asked for so far
+	if ok && wake {
+		select {
+		case f.wake <- struct{}{}:
+		default:
+		}
+	}
+
+	if f.current != nil && subscriber.resources[EventCurrent] {
+		subscriber.Events <- Event{Type: EventCurrent, Data: *f.current}
+	}
+	if f.nowcast != nil && subscriber.resources[EventNowcast] {
+		subscriber.Events <- Event{Type: EventNowcast, Data: *f.nowcast}
+	}
+	if len(f.alerts) > 0 && subscriber.resources[EventAlerts] {
+		var events []models.AlertEvent
+		for _, alert := range f.alerts {
+			events = append(events, models.AlertEvent{Type: models.AlertEventNew, Alert: alert})
+		}
+		subscriber.Events <- Event{Type: EventAlerts, Data: events}
+	}
+	return subscriber
+}
+
+// Close stops the subscription. The feed's refresher stops with its last subscriber.
+func (s *Subscriber) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+// remove must be called with the hub lock held
+func (h *Hub) remove(s *Subscriber) {
+	f := s.feed
+	if _, ok := f.subscribers[s]; !ok {
+		return
+	}
+	delete(f.subscribers, s)
+	close(s.Events)
+
+	if len(f.subscribers) == 0 {
+		close(f.stop)
+		delete(h.feeds, f.location.key())
+	}
+}
+
+// refresh polls the feed's location until its last subscriber leaves
+func (h *Hub) refresh(f *feed) {
+	ticker := time.NewTicker(config.StreamRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		h.poll(f)
+		select {
+		case <-f.stop:
+			return
+		case <-ticker.C:
+		case <-f.wake:
+		}
+	}
+}
+
+// wanted reports which resources any subscriber of the feed asks for
+func (h *Hub) wanted(f *feed) map[string]bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.wantedLocked(f)
+}
+
+func (h *Hub) wantedLocked(f *feed) map[string]bool {
+	wanted := make(map[string]bool)
+	for subscriber := range f.subscribers {
+		for resource := range subscriber.resources {
+			wanted[resource] = true
+		}
+	}
+	return wanted
+}
+
+// poll fetches only the resources somebody is subscribed to
+func (h *Hub) poll(f *feed) {
+	location := f.location
+	latitude := fmt.Sprintf("%.2f", location.Latitude)
+	longitude := fmt.Sprintf("%.2f", location.Longitude)
+	wanted := h.wanted(f)
+	// Feeds outlive the requests that started them
+	ctx := logging.NewContext(config.Ctx, logging.L().With(zap.String("stream", location.key())))
+
+	// Current conditions skip the forecast cache, whose TTL is far longer
+	// than the refresh interval. Failed fetches return an empty result and
+	// keep the previous conditions.
+	var current *models.CurrentWeatherResult
+	if wanted[EventCurrent] {
+		var weatherResult models.WeatherResult
+		switch location.Source {
+		case "qweather":
+			weatherResult = qweather.GetAllForecastDetails(cache.Bypass(ctx), latitude, longitude, location.Language, location.Unit)
+		default:
+			weatherResult = openmeteo.GetAllForecastDetails(cache.Bypass(ctx), latitude, longitude, location.Language, location.Unit)
+		}
+		if len(weatherResult.HWR) == 0 && len(weatherResult.DWR) == 0 {
+			logging.FromContext(ctx).Warn("Failed to refresh current weather", zap.String("provider", location.Source))
+		} else {
+			current = &weatherResult.CWR
+		}
+	}
+
+	var nowcast *models.NowcastResult
+	if wanted[EventNowcast] {
+		if result, err := openmeteo.GetNowcast(ctx, latitude, longitude); err != nil {
+			logging.FromContext(ctx).Warn("Failed to refresh nowcast", zap.String("provider", "openmeteo"), zap.Error(err))
+		} else {
+			nowcast = &result
+		}
+	}
+
+	// Keep the previous alerts of failed sources rather than reporting them
+	// as cancelled; nothing changes when every source failed
+	var alertList []models.Alert
+	var failedSources []string
+	alertsOK := false
+	if wanted[EventAlerts] {
+		var err error
+		if alertList, failedSources, err = alerts.Collect(ctx, h.sources(location.Source), location.Latitude, location.Longitude, location.Language); err != nil {
+			logging.FromContext(ctx).Warn("Failed to refresh alerts", zap.Error(err))
+		} else {
+			alertsOK = true
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if current != nil && (f.current == nil || !reflect.DeepEqual(*f.current, *current)) {
+		f.current = current
+		h.broadcast(f, Event{Type: EventCurrent, Data: *current})
+	}
+
+	if nowcast != nil && (f.nowcast == nil || !reflect.DeepEqual(*f.nowcast, *nowcast)) {
+		f.nowcast = nowcast
+		h.broadcast(f, Event{Type: EventNowcast, Data: *nowcast})
+	}
+
+	if alertsOK {
+		events, next := subscriptions.Diff(f.alerts, alertList, failedSources)
+		f.alerts = next
+		if len(events) > 0 {
+			h.broadcast(f, Event{Type: EventAlerts, Data: events})
+		}
+	}
+}
+
+// broadcast must be called with the hub lock held. Subscribers whose buffer
+// is full are dropped; they reconnect and receive a fresh snapshot.
+func (h *Hub) broadcast(f *feed, event Event) {
+	for subscriber := range f.subscribers {
+		if !subscriber.resources[event.Type] {
+			continue
+		}
+		select {
+		case subscriber.Events <- event:
+		default:
+			h.remove(subscriber)
+		}
+	}
+}
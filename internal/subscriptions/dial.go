@@ -0,0 +1,90 @@
+package subscriptions
+
+import (
+	"Zephyr/pkg/utils"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress is returned when a webhook resolves to an address that
+// is not publicly routable
+var ErrForbiddenAddress = errors.New("webhook address is not publicly routable")
+
+// Ranges that are neither private nor loopback by net.IP's definition but
+// still not public
+var reservedNetworks, _ = utils.ParseNetworks([]string{
+	"0.0.0.0/8",
+	"100.64.0.0/10",
+	"192.0.0.0/24",
+	"198.18.0.0/15",
+	"240.0.0.0/4",
+	"64:ff9b::/96",
+})
+
+// Networks webhooks may reach even though they are private, set from
+// WEBHOOK_ALLOWED_NETWORKS
+var allowedNetworks []*net.IPNet
+
+// SetAllowedNetworks parses the CIDRs and IPs that webhooks may reach
+// despite being private, e.g. receivers inside the cluster
+func SetAllowedNetworks(entries []string) error {
+	networks, err := utils.ParseNetworks(entries)
+	if err != nil {
+		return err
+	}
+	allowedNetworks = networks
+	return nil
+}
+
+// isAllowedIP reports whether a webhook may connect to the address
+func isAllowedIP(ip net.IP) bool {
+	if utils.ContainsIP(allowedNetworks, ip) {
+		return true
+	}
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !utils.ContainsIP(reservedNetworks, ip)
+}
+
+// dialControl runs after DNS resolution for every connection, including
+// redirects, so a hostname that later resolves to a private address is
+// still refused
+func dialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !isAllowedIP(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+	return nil
+}
+
+// NewWebhookClient returns a client that only connects to public addresses
+// and the allowed networks. Proxies are not used since they would dial on
+// our behalf without the check.
+func NewWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: dialControl}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// CheckWebhookHost resolves a webhook host and rejects it when any of its
+// addresses is forbidden. Connections are checked again when dialing.
+func CheckWebhookHost(ctx context.Context, host string) error {
+	addresses, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+	for _, address := range addresses {
+		if !isAllowedIP(address.IP) {
+			return fmt.Errorf("%w: %s", ErrForbiddenAddress, address.IP)
+		}
+	}
+	return nil
+}
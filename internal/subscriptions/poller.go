@@ -0,0 +1,176 @@
+package subscriptions
+
+import (
+	"Zephyr/internal/alerts"
+	"Zephyr/internal/config"
+	"Zephyr/internal/logging"
+	"Zephyr/internal/models"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Poller periodically checks alerts for every subscribed location and
+// notifies subscribers of changes
+type Poller struct {
+	Interval  time.Duration
+	Sources   func(source string) []alerts.Source
+	Deliverer Deliverer
+}
+
+// NewPoller configures a poller from the environment configuration
+func NewPoller(sources func(source string) []alerts.Source) *Poller {
+	return &Poller{
+		Interval: config.AlertPollInterval,
+		Sources:  sources,
+		Deliverer: Deliverer{
+			Client:      NewWebhookClient(config.WebhookTimeout),
+			MaxAttempts: config.WebhookMaxAttempts,
+			BaseBackoff: time.Second,
+		},
+	}
+}
+
+// Run polls until the context is cancelled
+func (p *Poller) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+
+	for {
+		p.Poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll fetches alerts once per distinct location and delivers the changes
+// to each subscription at that location
+func (p *Poller) Poll(ctx context.Context) {
+	subscriptionList, err := List()
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to list alert subscriptions", zap.Error(err))
+		return
+	}
+
+	// Subscriptions within ~1.11 km sharing language and source share one fetch
+	groups := make(map[string][]models.Subscription)
+	for _, subscription := range subscriptionList {
+		key := fmt.Sprintf("%.2f:%.2f:%s:%s", subscription.Latitude, subscription.Longitude,
+			subscription.Language, subscription.Source)
+		groups[key] = append(groups[key], subscription)
+	}
+
+	var wg sync.WaitGroup
+	for _, group := range groups {
+		first := group[0]
+		current, failed, err := alerts.Collect(ctx, p.Sources(first.Source), first.Latitude, first.Longitude, first.Language)
+		if err != nil {
+			logging.FromContext(ctx).Warn("Failed to fetch alerts for subscriptions", zap.Float64("latitude", first.Latitude), zap.Float64("longitude", first.Longitude), zap.Error(err))
+			continue
+		}
+
+		for _, subscription := range group {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				p.notify(ctx, subscription, current, failed)
+			}()
+		}
+	}
+	wg.Wait()
+}
+
+// notify delivers the difference between the current and the previously
+// delivered alerts. State only advances after a successful delivery, so a
+// failed webhook receives the same events on the next poll.
+func (p *Poller) notify(ctx context.Context, subscription models.Subscription, current []models.Alert, failed []string) {
+	seen, err := loadSeen(subscription.ID)
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to load delivered alerts", zap.String("subscription_id", subscription.ID), zap.Error(err))
+		return
+	}
+
+	events, next := Diff(seen, current, failed)
+	if len(events) == 0 {
+		return
+	}
+
+	payload := models.WebhookPayload{
+		SubscriptionID: subscription.ID,
+		SentAt:         time.Now().UTC(),
+		Events:         events,
+	}
+	if err := p.Deliverer.Deliver(ctx, subscription, payload); err != nil {
+		logging.FromContext(ctx).Warn("Failed to notify subscription", zap.String("subscription_id", subscription.ID), zap.Error(err))
+		return
+	}
+
+	if err := saveSeen(subscription.ID, next); err != nil {
+		logging.FromContext(ctx).Error("Failed to save delivered alerts", zap.String("subscription_id", subscription.ID), zap.Error(err))
+	}
+}
+
+// Diff compares current alerts with those previously delivered, keyed by
+// source and ID, and returns the events to send and the new delivered state.
+// Alerts that disappear from the sources are reported as cancelled, except
+// those of failed sources, which are kept until the source answers again.
+func Diff(seen map[string]models.Alert, current []models.Alert, failed []string) ([]models.AlertEvent, map[string]models.Alert) {
+	var events []models.AlertEvent
+	next := make(map[string]models.Alert, len(current))
+
+	for _, alert := range current {
+		key := alert.Source + ":" + alert.ID
+		previous, wasSeen := seen[key]
+
+		if alert.Status == "cancel" {
+			if wasSeen {
+				events = append(events, models.AlertEvent{Type: models.AlertEventCancelled, Alert: alert})
+			}
+			continue
+		}
+
+		next[key] = alert
+		switch {
+		case !wasSeen && alert.Status == "update":
+			events = append(events, models.AlertEvent{Type: models.AlertEventUpdated, Alert: alert})
+		case !wasSeen:
+			events = append(events, models.AlertEvent{Type: models.AlertEventNew, Alert: alert})
+		case previous.Status != alert.Status:
+			events = append(events, models.AlertEvent{Type: models.AlertEventUpdated, Alert: alert})
+		}
+	}
+
+	for key, alert := range seen {
+		if _, ok := next[key]; ok {
+			continue
+		}
+		// Already reported above when the source sent an explicit cancel
+		if containsAlert(current, key) {
+			continue
+		}
+		if slices.Contains(failed, alert.Source) {
+			next[key] = alert
+			continue
+		}
+		alert.Status = "cancel"
+		events = append(events, models.AlertEvent{Type: models.AlertEventCancelled, Alert: alert})
+	}
+
+	return events, next
+}
+
+func containsAlert(alertList []models.Alert, key string) bool {
+	for _, alert := range alertList {
+		if alert.Source+":"+alert.ID == key {
+			return true
+		}
+	}
+	return false
+}
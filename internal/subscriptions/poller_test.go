@@ -0,0 +1,119 @@
+package subscriptions
+
+import (
+	"Zephyr/internal/models"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	warning := models.Alert{ID: "w1", Source: "qweather", Status: "active"}
+	updated := models.Alert{ID: "w1", Source: "qweather", Status: "update"}
+	flood := models.Alert{ID: "f1", Source: "cap", Status: "active"}
+	seenOf := func(alertList ...models.Alert) map[string]models.Alert {
+		seen := make(map[string]models.Alert)
+		for _, alert := range alertList {
+			seen[alert.Source+":"+alert.ID] = alert
+		}
+		return seen
+	}
+
+	cases := []struct {
+		name    string
+		seen    map[string]models.Alert
+		current []models.Alert
+		failed  []string
+		events  map[string]string
+		next    []string
+	}{
+		{
+			name:    "new alert",
+			seen:    seenOf(),
+			current: []models.Alert{warning},
+			events:  map[string]string{"w1": models.AlertEventNew},
+			next:    []string{"qweather:w1"},
+		},
+		{
+			name:    "unchanged alert",
+			seen:    seenOf(warning),
+			current: []models.Alert{warning},
+			events:  map[string]string{},
+			next:    []string{"qweather:w1"},
+		},
+		{
+			name:    "status change",
+			seen:    seenOf(warning),
+			current: []models.Alert{updated},
+			events:  map[string]string{"w1": models.AlertEventUpdated},
+			next:    []string{"qweather:w1"},
+		},
+		{
+			name:    "first seen as update",
+			seen:    seenOf(),
+			current: []models.Alert{updated},
+			events:  map[string]string{"w1": models.AlertEventUpdated},
+			next:    []string{"qweather:w1"},
+		},
+		{
+			name:    "explicit cancel",
+			seen:    seenOf(warning),
+			current: []models.Alert{{ID: "w1", Source: "qweather", Status: "cancel"}},
+			events:  map[string]string{"w1": models.AlertEventCancelled},
+		},
+		{
+			name:    "cancel of an unseen alert",
+			seen:    seenOf(),
+			current: []models.Alert{{ID: "w1", Source: "qweather", Status: "cancel"}},
+			events:  map[string]string{},
+		},
+		{
+			name:    "disappeared alert",
+			seen:    seenOf(warning, flood),
+			current: []models.Alert{flood},
+			events:  map[string]string{"w1": models.AlertEventCancelled},
+			next:    []string{"cap:f1"},
+		},
+		{
+			name:    "failed source keeps its alerts",
+			seen:    seenOf(warning, flood),
+			current: []models.Alert{flood},
+			failed:  []string{"qweather"},
+			events:  map[string]string{},
+			next:    []string{"cap:f1", "qweather:w1"},
+		},
+		{
+			name:    "other sources still report changes while one fails",
+			seen:    seenOf(warning, flood),
+			current: []models.Alert{},
+			failed:  []string{"qweather"},
+			events:  map[string]string{"f1": models.AlertEventCancelled},
+			next:    []string{"qweather:w1"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			events, next := Diff(c.seen, c.current, c.failed)
+
+			got := make(map[string]string)
+			for _, event := range events {
+				got[event.Alert.ID] = event.Type
+			}
+			if len(got) != len(events) || len(got) != len(c.events) {
+				t.Fatalf("events = %v, want %v", events, c.events)
+			}
+			for id, eventType := range c.events {
+				if got[id] != eventType {
+					t.Errorf("event for %s = %q, want %q", id, got[id], eventType)
+				}
+			}
+
+			if len(next) != len(c.next) {
+				t.Fatalf("next state = %v, want keys %v", next, c.next)
+			}
+			for _, key := range c.next {
+				if _, ok := next[key]; !ok {
+					t.Errorf("next state is missing %s", key)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package subscriptions
+
+import (
+	"Zephyr/internal/config"
+	"Zephyr/internal/models"
+	"Zephyr/pkg/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// Set of all subscription IDs
+	subscriptionIndexKey = "subscriptions"
+	// Secrets are 32 random bytes, hex encoded
+	secretBytes = 32
+)
+
+// ErrNotFound is returned for unknown subscription IDs
+var ErrNotFound = errors.New("subscription not found")
+
+func subscriptionKey(id string) string {
+	return fmt.Sprintf("subscription:%s", id)
+}
+
+// seenKey holds the alerts last delivered to a subscription, keyed by source and ID
+func seenKey(id string) string {
+	return fmt.Sprintf("subscription:seen:%s", id)
+}
+
+// Create stores a new subscription with a generated ID and signing secret
+func Create(subscription models.Subscription) (models.Subscription, error) {
+	id, err := utils.RandomHex(16)
+	if err != nil {
+		return models.Subscription{}, err
+	}
+	secret, err := utils.RandomHex(secretBytes)
+	if err != nil {
+		return models.Subscription{}, err
+	}
+
+	subscription.ID = id
+	subscription.Secret = secret
+	subscription.CreatedAt = time.Now().UTC()
+
+	data, err := json.Marshal(subscription)
+	if err != nil {
+		return models.Subscription{}, err
+	}
+
+	pipe := config.RedisClient.TxPipeline()
+	pipe.Set(config.Ctx, subscriptionKey(id), data, 0)
+	pipe.SAdd(config.Ctx, subscriptionIndexKey, id)
+	if _, err := pipe.Exec(config.Ctx); err != nil {
+		return models.Subscription{}, err
+	}
+	return subscription, nil
+}
+
+// Get loads a subscription including its secret
+func Get(id string) (models.Subscription, error) {
+	data, err := config.RedisClient.Get(config.Ctx, subscriptionKey(id)).Bytes()
+	if err == redis.Nil {
+		return models.Subscription{}, ErrNotFound
+	}
+	if err != nil {
+		return models.Subscription{}, err
+	}
+
+	var subscription models.Subscription
+	if err := json.Unmarshal(data, &subscription); err != nil {
+		return models.Subscription{}, err
+	}
+	return subscription, nil
+}
+
+// Delete removes a subscription and its delivery state
+func Delete(id string) error {
+	pipe := config.RedisClient.TxPipeline()
+	delCmd := pipe.Del(config.Ctx, subscriptionKey(id))
+	pipe.Del(config.Ctx, seenKey(id))
+	pipe.SRem(config.Ctx, subscriptionIndexKey, id)
+	if _, err := pipe.Exec(config.Ctx); err != nil {
+		return err
+	}
+	if delCmd.Val() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// List returns every subscription, skipping entries that fail to load
+func List() ([]models.Subscription, error) {
+	ids, err := config.RedisClient.SMembers(config.Ctx, subscriptionIndexKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptionList := make([]models.Subscription, 0, len(ids))
+	for _, id := range ids {
+		subscription, err := Get(id)
+		if err != nil {
+			continue
+		}
+		subscriptionList = append(subscriptionList, subscription)
+	}
+	return subscriptionList, nil
+}
+
+// loadSeen returns the alerts last delivered to a subscription
+func loadSeen(id string) (map[string]models.Alert, error) {
+	values, err := config.RedisClient.HGetAll(config.Ctx, seenKey(id)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]models.Alert, len(values))
+	for key, value := range values {
+		var alert models.Alert
+		if err := json.Unmarshal([]byte(value), &alert); err == nil {
+			seen[key] = alert
+		}
+	}
+	return seen, nil
+}
+
+// saveSeen replaces the delivered alert state of a subscription
+func saveSeen(id string, seen map[string]models.Alert) error {
+	pipe := config.RedisClient.TxPipeline()
+	pipe.Del(config.Ctx, seenKey(id))
+	if len(seen) > 0 {
+		values := make(map[string]interface{}, len(seen))
+		for key, alert := range seen {
+			data, err := json.Marshal(alert)
+			if err != nil {
+				return err
+			}
+			values[key] = data
+		}
+		pipe.HSet(config.Ctx, seenKey(id), values)
+	}
+	_, err := pipe.Exec(config.Ctx)
+	return err
+}
@@ -0,0 +1,111 @@
+package subscriptions
+
+import (
+	"Zephyr/internal/models"
+	"Zephyr/internal/tracing"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Headers sent with every webhook delivery
+const (
+	SignatureHeader = "X-Zeus-Signature"
+	TimestampHeader = "X-Zeus-Timestamp"
+)
+
+// Sign computes the webhook signature over "<timestamp>.<body>".
+// Receivers recompute it with the subscription secret and compare.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Deliverer POSTs signed payloads, retrying with exponential backoff
+type Deliverer struct {
+	Client      *http.Client
+	MaxAttempts int
+	BaseBackoff time.Duration
+}
+
+// Deliver sends the payload to the subscription's webhook. Network errors,
+// 429 and 5xx responses are retried; other 4xx responses are final.
+// Cancelling the context stops delivery, including while backing off.
+func (d Deliverer) Deliver(ctx context.Context, subscription models.Subscription, payload models.WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	backoff := d.BaseBackoff
+	var lastErr error
+	for attempt := 1; attempt <= d.MaxAttempts; attempt++ {
+		retry, err := d.post(ctx, subscription, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry || attempt == d.MaxAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("webhook delivery to %s cancelled: %w", subscription.WebhookURL, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return fmt.Errorf("webhook delivery to %s failed: %w", subscription.WebhookURL, lastErr)
+}
+
+// post makes one delivery attempt and reports whether a failure is retryable
+func (d Deliverer) post(ctx context.Context, subscription models.Subscription, body []byte) (bool, error) {
+	ctx, span := tracing.Start(ctx, "POST webhook",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("subscription_id", subscription.ID)),
+	)
+	defer span.End()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	// Sign at send time so retries carry a fresh timestamp
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, timestamp, body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	span.SetStatus(codes.Error, resp.Status)
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+}
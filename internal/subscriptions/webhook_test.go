@@ -0,0 +1,158 @@
+package subscriptions
+
+import (
+	"Zephyr/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testDeliverer() Deliverer {
+	return Deliverer{Client: http.DefaultClient, MaxAttempts: 3, BaseBackoff: time.Millisecond}
+}
+
+func TestDeliverSignsPayload(t *testing.T) {
+	subscription := models.Subscription{ID: "sub-1", Secret: "whsec_test"}
+	payload := models.WebhookPayload{
+		SubscriptionID: subscription.ID,
+		Events:         []models.AlertEvent{{Type: models.AlertEventNew, Alert: models.Alert{ID: "a1", Source: "qweather"}}},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, err := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+		if err != nil {
+			t.Errorf("invalid %s header: %v", TimestampHeader, err)
+		}
+		if got, want := r.Header.Get(SignatureHeader), Sign(subscription.Secret, timestamp, body); got != want {
+			t.Errorf("%s = %q, want %q", SignatureHeader, got, want)
+		}
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type = %q", r.Header.Get("Content-Type"))
+		}
+
+		var received models.WebhookPayload
+		if err := json.Unmarshal(body, &received); err != nil || received.SubscriptionID != "sub-1" || len(received.Events) != 1 {
+			t.Errorf("unexpected payload %s: %v", body, err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	subscription.WebhookURL = server.URL
+	if err := testDeliverer().Deliver(context.Background(), subscription, payload); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSignatureDependsOnSecretAndTimestamp(t *testing.T) {
+	body := []byte(`{"events":[]}`)
+	signature := Sign("secret", 1700000000, body)
+	if signature != Sign("secret", 1700000000, body) {
+		t.Error("signature is not deterministic")
+	}
+	if signature == Sign("other", 1700000000, body) || signature == Sign("secret", 1700000001, body) {
+		t.Error("signature ignores the secret or timestamp")
+	}
+}
+
+func TestDeliverRetries(t *testing.T) {
+	cases := []struct {
+		name     string
+		statuses []int
+		attempts int32
+		ok       bool
+	}{
+		{"success", []int{http.StatusOK}, 1, true},
+		{"429 then success", []int{http.StatusTooManyRequests, http.StatusOK}, 2, true},
+		{"5xx then success", []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK}, 3, true},
+		{"5xx until attempts run out", []int{http.StatusInternalServerError}, 3, false},
+		{"400 is final", []int{http.StatusBadRequest}, 1, false},
+		{"404 is final", []int{http.StatusNotFound}, 1, false},
+		{"410 after 503 is final", []int{http.StatusServiceUnavailable, http.StatusGone}, 2, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(attempts.Add(1))
+				w.WriteHeader(c.statuses[min(n, len(c.statuses))-1])
+			}))
+			defer server.Close()
+
+			err := testDeliverer().Deliver(context.Background(), models.Subscription{WebhookURL: server.URL}, models.WebhookPayload{})
+			if (err == nil) != c.ok {
+				t.Errorf("Deliver error = %v, want ok %v", err, c.ok)
+			}
+			if got := attempts.Load(); got != c.attempts {
+				t.Errorf("got %d attempts, want %d", got, c.attempts)
+			}
+		})
+	}
+}
+
+func TestDeliverStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	deliverer := Deliverer{Client: http.DefaultClient, MaxAttempts: 5, BaseBackoff: time.Hour}
+	err := deliverer.Deliver(ctx, models.Subscription{WebhookURL: server.URL}, models.WebhookPayload{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Deliver error = %v, want context.Canceled", err)
+	}
+}
+
+func TestWebhookClientRefusesPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	t.Cleanup(func() { allowedNetworks = nil })
+
+	_, err := NewWebhookClient(time.Second).Get(server.URL)
+	if !errors.Is(err, ErrForbiddenAddress) {
+		t.Fatalf("loopback webhook error = %v, want ErrForbiddenAddress", err)
+	}
+
+	if err := SetAllowedNetworks([]string{"127.0.0.0/8", "::1"}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := NewWebhookClient(time.Second).Get(server.URL)
+	if err != nil {
+		t.Fatalf("allow-listed webhook failed: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestIsAllowedIP(t *testing.T) {
+	cases := map[string]bool{
+		"8.8.8.8":              true,
+		"2001:4860:4860::8888": true,
+		"127.0.0.1":            false,
+		"10.1.2.3":             false,
+		"172.16.0.1":           false,
+		"192.168.1.1":          false,
+		"169.254.169.254":      false,
+		"100.64.0.1":           false,
+		"0.0.0.0":              false,
+		"::1":                  false,
+		"fe80::1":              false,
+		"fd00::1":              false,
+		"::ffff:127.0.0.1":     false,
+	}
+	for address, want := range cases {
+		if got := isAllowedIP(net.ParseIP(address)); got != want {
+			t.Errorf("isAllowedIP(%s) = %v, want %v", address, got, want)
+		}
+	}
+}
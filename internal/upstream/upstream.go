@@ -0,0 +1,106 @@
+// Package upstream performs requests to weather and geocoding providers and
+// logs, measures and traces each call with its provider, endpoint, status
+// and latency.
+package upstream
+
+import (
+	"Zephyr/internal/logging"
+	"Zephyr/internal/metrics"
+	"Zephyr/internal/tracing"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Client is shared by all providers
+var Client = http.DefaultClient
+
+// Get fetches a provider URL
+func Get(ctx context.Context, provider, endpoint, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return Do(ctx, provider, endpoint, req)
+}
+
+// Do sends a prepared request. The endpoint is a fixed path template such as
+// "/airquality/v1/hourly/{lat}/{lon}" supplied by the caller, never the raw
+// path, so coordinates and feed URLs cannot multiply metric series. The
+// request's span ends when the response body is closed so it covers the
+// download.
+func Do(ctx context.Context, provider, endpoint string, req *http.Request) (*http.Response, error) {
+	ctx, span := tracing.Start(ctx, req.Method+" "+endpoint,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("provider", provider),
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.URLTemplate(endpoint),
+			semconv.ServerAddress(req.URL.Hostname()),
+		),
+	)
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	start := time.Now()
+	resp, err := Client.Do(req)
+	latency := time.Since(start)
+	metrics.UpstreamRequestDuration.WithLabelValues(provider, endpoint).Observe(latency.Seconds())
+
+	logger := logging.FromContext(ctx).With(
+		zap.String("provider", provider),
+		zap.String("endpoint", endpoint),
+		zap.Duration("latency", latency),
+	)
+	if err != nil {
+		metrics.UpstreamRequests.WithLabelValues(provider, endpoint, "error").Inc()
+		metrics.UpstreamErrors.WithLabelValues(provider, endpoint).Inc()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.End()
+		logger.Warn("Upstream request failed", zap.Error(err))
+		return nil, err
+	}
+	metrics.UpstreamRequests.WithLabelValues(provider, endpoint, strconv.Itoa(resp.StatusCode)).Inc()
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		metrics.UpstreamErrors.WithLabelValues(provider, endpoint).Inc()
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	logger.Info("Upstream request", zap.Int("status", resp.StatusCode))
+
+	resp.Body = &tracedBody{ReadCloser: resp.Body, span: span}
+	return resp, nil
+}
+
+// tracedBody counts the bytes read from a response and ends its span on Close
+type tracedBody struct {
+	io.ReadCloser
+	span  trace.Span
+	bytes int
+}
+
+func (b *tracedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.bytes += n
+	return n, err
+}
+
+func (b *tracedBody) Close() error {
+	err := b.ReadCloser.Close()
+	if b.span.IsRecording() {
+		b.span.SetAttributes(semconv.HTTPResponseBodySize(b.bytes))
+		b.span.End()
+	}
+	return err
+}
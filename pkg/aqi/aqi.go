@@ -0,0 +1,301 @@
+package aqi
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Standard identifies the national or regional index an AQI value is expressed in.
+type Standard string
+
+const (
+	// USEPA is the United States EPA Air Quality Index (0-500)
+	USEPA Standard = "us_epa"
+	// EUCAQI is the European Common Air Quality Index (0-100, open ended)
+	EUCAQI Standard = "eu_caqi"
+	// ChinaHJ633 is the China MEE index defined by HJ 633-2012 (0-500)
+	ChinaHJ633 Standard = "cn_hj633"
+	// UKDAQI is the UK Daily Air Quality Index (bands 1-10)
+	UKDAQI Standard = "uk_daqi"
+	// IndiaNAQI is India's National Air Quality Index (0-500)
+	IndiaNAQI Standard = "in_naqi"
+)
+
+// Standards lists every supported standard in a stable order
+var Standards = []Standard{USEPA, EUCAQI, ChinaHJ633, UKDAQI, IndiaNAQI}
+
+// ParseStandard validates a standard name received from a client
+func ParseStandard(name string) (Standard, error) {
+	standard := Standard(strings.ToLower(strings.TrimSpace(name)))
+	if _, ok := scales[standard]; !ok {
+		return "", fmt.Errorf("unsupported AQI standard: %s", name)
+	}
+	return standard, nil
+}
+
+// Pollutant names, matching the JSON field names used in the API models
+const (
+	PM25 = "pm2_5"
+	PM10 = "pm10"
+	O3   = "ozone"
+	NO2  = "nitrogen_dioxide"
+	SO2  = "sulfur_dioxide"
+	CO   = "carbon_monoxide"
+)
+
+// pollutantOrder keeps primary pollutant selection deterministic on ties
+var pollutantOrder = []string{PM25, PM10, O3, NO2, SO2, CO}
+
+// Molecular weights (g/mol) used to convert µg/m³ to ppb at 25°C and 1 atm
+var molecularWeight = map[string]float64{
+	O3:  48.00,
+	NO2: 46.01,
+	SO2: 64.07,
+	CO:  28.01,
+}
+
+// Concentrations holds pollutant concentrations, all in µg/m³.
+// Values are treated as the averaging period each standard expects;
+// callers pass hourly values for hourly data and daily means for daily data.
+type Concentrations struct {
+	PM25 float64
+	PM10 float64
+	O3   float64
+	NO2  float64
+	SO2  float64
+	CO   float64
+}
+
+func (c Concentrations) get(pollutant string) float64 {
+	switch pollutant {
+	case PM25:
+		return c.PM25
+	case PM10:
+		return c.PM10
+	case O3:
+		return c.O3
+	case NO2:
+		return c.NO2
+	case SO2:
+		return c.SO2
+	case CO:
+		return c.CO
+	}
+	return 0
+}
+
+// Result is an index value computed for a single standard
+type Result struct {
+	Standard         Standard
+	Value            int
+	PrimaryPollutant string
+	Category         string
+	HealthAdvice     string
+}
+
+// breakpoint maps a concentration range onto an index range
+type breakpoint struct {
+	cLow, cHigh float64
+	iLow, iHigh float64
+}
+
+// scale describes how one standard turns concentrations into sub-indices
+type scale struct {
+	tables map[string][]breakpoint
+	// convert adapts a µg/m³ concentration to the unit and precision of the table
+	convert func(pollutant string, value float64) float64
+	// openEnded extrapolates past the last breakpoint instead of clamping
+	openEnded bool
+	// primaryThreshold is the sub-index a pollutant must exceed to be reported as primary
+	primaryThreshold float64
+}
+
+var scales = map[Standard]scale{
+	USEPA: {
+		tables: map[string][]breakpoint{
+			// 24-hour µg/m³ (2024 revision)
+			PM25: {{0, 9.0, 0, 50}, {9.1, 35.4, 51, 100}, {35.5, 55.4, 101, 150}, {55.5, 125.4, 151, 200}, {125.5, 225.4, 201, 300}, {225.5, 325.4, 301, 500}},
+			// 24-hour µg/m³
+			PM10: {{0, 54, 0, 50}, {55, 154, 51, 100}, {155, 254, 101, 150}, {255, 354, 151, 200}, {355, 424, 201, 300}, {425, 604, 301, 500}},
+			// 8-hour ppb
+			O3: {{0, 54, 0, 50}, {55, 70, 51, 100}, {71, 85, 101, 150}, {86, 105, 151, 200}, {106, 200, 201, 300}},
+			// 1-hour ppb
+			NO2: {{0, 53, 0, 50}, {54, 100, 51, 100}, {101, 360, 101, 150}, {361, 649, 151, 200}, {650, 1249, 201, 300}, {1250, 2049, 301, 500}},
+			// 1-hour ppb, 24-hour above 304 ppb
+			SO2: {{0, 35, 0, 50}, {36, 75, 51, 100}, {76, 185, 101, 150}, {186, 304, 151, 200}, {305, 604, 201, 300}, {605, 1004, 301, 500}},
+			// 8-hour ppm
+			CO: {{0, 4.4, 0, 50}, {4.5, 9.4, 51, 100}, {9.5, 12.4, 101, 150}, {12.5, 15.4, 151, 200}, {15.5, 30.4, 201, 300}, {30.5, 50.4, 301, 500}},
+		},
+		convert: func(pollutant string, value float64) float64 {
+			switch pollutant {
+			case PM25:
+				return truncate(value, 1)
+			case PM10:
+				return truncate(value, 0)
+			case CO:
+				return truncate(toPPB(pollutant, value)/1000, 1)
+			default:
+				return truncate(toPPB(pollutant, value), 0)
+			}
+		},
+	},
+	EUCAQI: {
+		// Hourly background grid, µg/m³
+		tables: map[string][]breakpoint{
+			PM25: {{0, 15, 0, 25}, {15, 30, 25, 50}, {30, 55, 50, 75}, {55, 110, 75, 100}},
+			PM10: {{0, 25, 0, 25}, {25, 50, 25, 50}, {50, 90, 50, 75}, {90, 180, 75, 100}},
+			O3:   {{0, 60, 0, 25}, {60, 120, 25, 50}, {120, 180, 50, 75}, {180, 240, 75, 100}},
+			NO2:  {{0, 50, 0, 25}, {50, 100, 25, 50}, {100, 200, 50, 75}, {200, 400, 75, 100}},
+			SO2:  {{0, 50, 0, 25}, {50, 100, 25, 50}, {100, 350, 50, 75}, {350, 500, 75, 100}},
+			CO:   {{0, 5000, 0, 25}, {5000, 7500, 25, 50}, {7500, 10000, 50, 75}, {10000, 20000, 75, 100}},
+		},
+		convert: func(_ string, value float64) float64 {
+			return value
+		},
+		openEnded: true,
+	},
+	ChinaHJ633: {
+		tables: map[string][]breakpoint{
+			// 24-hour µg/m³
+			PM25: {{0, 35, 0, 50}, {35, 75, 50, 100}, {75, 115, 100, 150}, {115, 150, 150, 200}, {150, 250, 200, 300}, {250, 350, 300, 400}, {350, 500, 400, 500}},
+			// 24-hour µg/m³
+			PM10: {{0, 50, 0, 50}, {50, 150, 50, 100}, {150, 250, 100, 150}, {250, 350, 150, 200}, {350, 420, 200, 300}, {420, 500, 300, 400}, {500, 600, 400, 500}},
+			// 1-hour µg/m³
+			O3: {{0, 160, 0, 50}, {160, 200, 50, 100}, {200, 300, 100, 150}, {300, 400, 150, 200}, {400, 800, 200, 300}, {800, 1000, 300, 400}, {1000, 1200, 400, 500}},
+			// 1-hour µg/m³
+			NO2: {{0, 100, 0, 50}, {100, 200, 50, 100}, {200, 700, 100, 150}, {700, 1200, 150, 200}, {1200, 2340, 200, 300}, {2340, 3090, 300, 400}, {3090, 3840, 400, 500}},
+			// 1-hour µg/m³, 24-hour breakpoints above 800 µg/m³
+			SO2: {{0, 150, 0, 50}, {150, 500, 50, 100}, {500, 650, 100, 150}, {650, 800, 150, 200}, {800, 1600, 200, 300}, {1600, 2100, 300, 400}, {2100, 2620, 400, 500}},
+			// 1-hour mg/m³
+			CO: {{0, 5, 0, 50}, {5, 10, 50, 100}, {10, 35, 100, 150}, {35, 60, 150, 200}, {60, 90, 200, 300}, {90, 120, 300, 400}, {120, 150, 400, 500}},
+		},
+		convert: func(pollutant string, value float64) float64 {
+			if pollutant == CO {
+				return value / 1000
+			}
+			return value
+		},
+		primaryThreshold: 50,
+	},
+	UKDAQI: {
+		// Banded rather than interpolated, µg/m³: 8-hour O3, 1-hour NO2,
+		// 15-minute SO2 and 24-hour particulates
+		tables: map[string][]breakpoint{
+			PM25: daqiBands(11, 23, 35, 41, 47, 53, 58, 64, 70),
+			PM10: daqiBands(16, 33, 50, 58, 66, 75, 83, 91, 100),
+			O3:   daqiBands(33, 66, 100, 120, 140, 160, 187, 213, 240),
+			NO2:  daqiBands(67, 134, 200, 267, 334, 400, 467, 534, 600),
+			SO2:  daqiBands(88, 177, 266, 354, 443, 532, 710, 887, 1064),
+		},
+		convert: func(_ string, value float64) float64 {
+			return math.Round(value)
+		},
+	},
+	IndiaNAQI: {
+		tables: map[string][]breakpoint{
+			// 24-hour µg/m³
+			PM25: {{0, 30, 0, 50}, {31, 60, 51, 100}, {61, 90, 101, 200}, {91, 120, 201, 300}, {121, 250, 301, 400}, {251, 380, 401, 500}},
+			// 24-hour µg/m³
+			PM10: {{0, 50, 0, 50}, {51, 100, 51, 100}, {101, 250, 101, 200}, {251, 350, 201, 300}, {351, 430, 301, 400}, {431, 600, 401, 500}},
+			// 8-hour µg/m³
+			O3: {{0, 50, 0, 50}, {51, 100, 51, 100}, {101, 168, 101, 200}, {169, 208, 201, 300}, {209, 748, 301, 400}, {749, 1000, 401, 500}},
+			// 24-hour µg/m³
+			NO2: {{0, 40, 0, 50}, {41, 80, 51, 100}, {81, 180, 101, 200}, {181, 280, 201, 300}, {281, 400, 301, 400}, {401, 800, 401, 500}},
+			// 24-hour µg/m³
+			SO2: {{0, 40, 0, 50}, {41, 80, 51, 100}, {81, 380, 101, 200}, {381, 800, 201, 300}, {801, 1600, 301, 400}, {1601, 2400, 401, 500}},
+			// 8-hour mg/m³
+			CO: {{0, 1.0, 0, 50}, {1.1, 2.0, 51, 100}, {2.1, 10, 101, 200}, {10.1, 17, 201, 300}, {17.1, 34, 301, 400}, {34.1, 50, 401, 500}},
+		},
+		convert: func(pollutant string, value float64) float64 {
+			if pollutant == CO {
+				return truncate(value/1000, 1)
+			}
+			return math.Round(value)
+		},
+	},
+}
+
+// Calculate computes the index for the given standard from pollutant
+// concentrations. The overall value is the highest pollutant sub-index.
+func Calculate(standard Standard, c Concentrations) (Result, error) {
+	s, ok := scales[standard]
+	if !ok {
+		return Result{}, fmt.Errorf("unsupported AQI standard: %s", standard)
+	}
+
+	result := Result{Standard: standard}
+	highest := -1.0
+	for _, pollutant := range pollutantOrder {
+		value := c.get(pollutant)
+		if value <= 0 {
+			continue
+		}
+		table, ok := s.tables[pollutant]
+		if !ok {
+			continue
+		}
+		subIndex := interpolate(table, s.convert(pollutant, value), s.openEnded)
+		if subIndex > highest {
+			highest = subIndex
+			if subIndex > s.primaryThreshold {
+				result.PrimaryPollutant = pollutant
+			} else {
+				result.PrimaryPollutant = ""
+			}
+		}
+	}
+
+	if highest > 0 {
+		result.Value = int(math.Round(highest))
+	}
+	result.Category, result.HealthAdvice = categorize(standard, result.Value)
+	return result, nil
+}
+
+// interpolate applies the piecewise linear AQI formula to a concentration
+func interpolate(table []breakpoint, c float64, openEnded bool) float64 {
+	for _, bp := range table {
+		if c <= bp.cHigh {
+			// Values falling into the gap between two rows snap to the upper row
+			if c < bp.cLow {
+				c = bp.cLow
+			}
+			return (bp.iHigh-bp.iLow)/(bp.cHigh-bp.cLow)*(c-bp.cLow) + bp.iLow
+		}
+	}
+
+	last := table[len(table)-1]
+	if openEnded {
+		return (last.iHigh-last.iLow)/(last.cHigh-last.cLow)*(c-last.cLow) + last.iLow
+	}
+	return last.iHigh
+}
+
+// daqiBands builds DAQI index bands 1-10 from the upper bound of bands 1-9
+func daqiBands(upperBounds ...float64) []breakpoint {
+	bands := make([]breakpoint, 0, len(upperBounds)+1)
+	low := 0.0
+	for i, high := range upperBounds {
+		index := float64(i + 1)
+		bands = append(bands, breakpoint{low, high, index, index})
+		low = high + 1
+	}
+	top := float64(len(upperBounds) + 1)
+	return append(bands, breakpoint{low, math.Inf(1), top, top})
+}
+
+// toPPB converts a gas concentration from µg/m³ to ppb at 25°C and 1 atm
+func toPPB(pollutant string, value float64) float64 {
+	weight, ok := molecularWeight[pollutant]
+	if !ok {
+		return value
+	}
+	return value * 24.45 / weight
+}
+
+// truncate drops digits beyond the given precision, as the EPA method requires
+func truncate(value float64, digits int) float64 {
+	pow := math.Pow(10, float64(digits))
+	return math.Floor(value*pow) / pow
+}
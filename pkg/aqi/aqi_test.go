@@ -0,0 +1,102 @@
+package aqi
+
+import "testing"
+
+func TestCalculate(t *testing.T) {
+	cases := []struct {
+		name     string
+		standard Standard
+		c        Concentrations
+		value    int
+		primary  string
+		category string
+	}{
+		// US EPA: breakpoint edges, truncation and clamping
+		{"us pm2.5 top of good", USEPA, Concentrations{PM25: 9.0}, 50, PM25, "Good"},
+		{"us pm2.5 truncated to one decimal", USEPA, Concentrations{PM25: 9.09}, 50, PM25, "Good"},
+		{"us pm2.5 bottom of moderate", USEPA, Concentrations{PM25: 9.1}, 51, PM25, "Moderate"},
+		{"us pm2.5 top of moderate", USEPA, Concentrations{PM25: 35.49}, 100, PM25, "Moderate"},
+		{"us pm2.5 above top breakpoint", USEPA, Concentrations{PM25: 900}, 500, PM25, "Hazardous"},
+		{"us pm10 truncated to integer", USEPA, Concentrations{PM10: 54.9}, 50, PM10, "Good"},
+		{"us pm10 bottom of moderate", USEPA, Concentrations{PM10: 55}, 51, PM10, "Moderate"},
+		{"us ozone converted to ppb", USEPA, Concentrations{O3: 100}, 46, O3, "Good"},
+		{"us co converted to ppm", USEPA, Concentrations{CO: 5000}, 49, CO, "Good"},
+		{"us highest sub-index wins", USEPA, Concentrations{PM25: 9.0, PM10: 155}, 101, PM10, "Unhealthy for Sensitive Groups"},
+		{"us no data", USEPA, Concentrations{}, 0, "", "Good"},
+
+		// EU CAQI: continuous bands, open ended above the last one
+		{"eu pm2.5 band edge", EUCAQI, Concentrations{PM25: 15}, 25, PM25, "Very Low"},
+		{"eu pm10 band edge", EUCAQI, Concentrations{PM10: 50}, 50, PM10, "Low"},
+		{"eu pm2.5 top breakpoint", EUCAQI, Concentrations{PM25: 110}, 100, PM25, "High"},
+		{"eu pm2.5 extrapolated", EUCAQI, Concentrations{PM25: 165}, 125, PM25, "Very High"},
+
+		// China HJ 633: primary pollutant only above 50
+		{"cn pm2.5 top of excellent", ChinaHJ633, Concentrations{PM25: 35}, 50, "", "Excellent"},
+		{"cn pm2.5 top of good", ChinaHJ633, Concentrations{PM25: 75}, 100, PM25, "Good"},
+		{"cn co converted to mg", ChinaHJ633, Concentrations{CO: 10000}, 100, CO, "Good"},
+		{"cn pm2.5 above top breakpoint", ChinaHJ633, Concentrations{PM25: 800}, 500, PM25, "Severely Polluted"},
+
+		// UK DAQI: banded on rounded concentrations, no CO band
+		{"uk pm2.5 top of band 1", UKDAQI, Concentrations{PM25: 11.4}, 1, PM25, "Low"},
+		{"uk pm2.5 rounds into band 2", UKDAQI, Concentrations{PM25: 11.6}, 2, PM25, "Low"},
+		{"uk pm10 bottom of moderate", UKDAQI, Concentrations{PM10: 51}, 4, PM10, "Moderate"},
+		{"uk ozone top of band 9", UKDAQI, Concentrations{O3: 240}, 9, O3, "High"},
+		{"uk no2 above band 9", UKDAQI, Concentrations{NO2: 601}, 10, NO2, "Very High"},
+		{"uk far above the top band", UKDAQI, Concentrations{SO2: 5000}, 10, SO2, "Very High"},
+		{"uk co is not banded", UKDAQI, Concentrations{CO: 20000}, 0, "", "Low"},
+
+		// India NAQI: rounded concentrations, CO truncated in mg/m³
+		{"in pm2.5 top of good", IndiaNAQI, Concentrations{PM25: 30.4}, 50, PM25, "Good"},
+		{"in pm2.5 rounds into satisfactory", IndiaNAQI, Concentrations{PM25: 30.6}, 51, PM25, "Satisfactory"},
+		{"in pm2.5 bottom of moderate", IndiaNAQI, Concentrations{PM25: 61}, 101, PM25, "Moderate"},
+		{"in pm10 top of poor", IndiaNAQI, Concentrations{PM10: 350}, 300, PM10, "Poor"},
+		{"in co truncated to one decimal", IndiaNAQI, Concentrations{CO: 1050}, 50, CO, "Good"},
+		{"in co bottom of satisfactory", IndiaNAQI, Concentrations{CO: 1100}, 51, CO, "Satisfactory"},
+		{"in pm2.5 above top breakpoint", IndiaNAQI, Concentrations{PM25: 1000}, 500, PM25, "Severe"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			result, err := Calculate(c.standard, c.c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Value != c.value || result.PrimaryPollutant != c.primary || result.Category != c.category {
+				t.Errorf("Calculate = %d %q %q, want %d %q %q",
+					result.Value, result.PrimaryPollutant, result.Category, c.value, c.primary, c.category)
+			}
+		})
+	}
+
+	if _, err := Calculate("us_aqi", Concentrations{PM25: 10}); err == nil {
+		t.Error("expected an error for an unknown standard")
+	}
+}
+
+func TestParseStandard(t *testing.T) {
+	cases := []struct {
+		name     string
+		standard Standard
+		ok       bool
+	}{
+		{"us_epa", USEPA, true},
+		{" US_EPA ", USEPA, true},
+		{"eu_caqi", EUCAQI, true},
+		{"cn_hj633", ChinaHJ633, true},
+		{"uk_daqi", UKDAQI, true},
+		{"in_naqi", IndiaNAQI, true},
+		{"epa", "", false},
+		{"", "", false},
+	}
+	for _, c := range cases {
+		standard, err := ParseStandard(c.name)
+		if (err == nil) != c.ok || standard != c.standard {
+			t.Errorf("ParseStandard(%q) = %q, %v", c.name, standard, err)
+		}
+	}
+
+	for _, standard := range Standards {
+		if _, err := ParseStandard(string(standard)); err != nil {
+			t.Errorf("listed standard %s does not parse: %v", standard, err)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package aqi
+
+// category is the upper index bound of a named band
+type category struct {
+	max    int
+	name   string
+	advice string
+}
+
+var categories = map[Standard][]category{
+	USEPA: {
+		{50, "Good", "Air quality is satisfactory and poses little or no risk."},
+		{100, "Moderate", "Unusually sensitive people should consider reducing prolonged or heavy outdoor exertion."},
+		{150, "Unhealthy for Sensitive Groups", "People with heart or lung disease, older adults and children should reduce prolonged or heavy outdoor exertion."},
+		{200, "Unhealthy", "Everyone should reduce prolonged or heavy outdoor exertion; sensitive groups should avoid it."},
+		{300, "Very Unhealthy", "Everyone should avoid prolonged or heavy outdoor exertion; sensitive groups should stay indoors."},
+		{500, "Hazardous", "Everyone should avoid all outdoor physical activity."},
+	},
+	EUCAQI: {
+		{25, "Very Low", "Air quality is very good; enjoy outdoor activities."},
+		{50, "Low", "Air quality is good; no precautions are needed."},
+		{75, "Medium", "Sensitive people should consider limiting intense outdoor activity."},
+		{100, "High", "Sensitive people should avoid intense outdoor activity; others should reduce it."},
+		{-1, "Very High", "Everyone should avoid intense outdoor activity; sensitive people should stay indoors."},
+	},
+	ChinaHJ633: {
+		{50, "Excellent", "Air quality is satisfactory with almost no pollution."},
+		{100, "Good", "Air quality is acceptable; a few unusually sensitive people should reduce outdoor activity."},
+		{150, "Lightly Polluted", "Children, older adults and people with heart or respiratory disease should reduce prolonged or intense outdoor exercise."},
+		{200, "Moderately Polluted", "Sensitive groups should avoid prolonged or intense outdoor exercise; others should reduce outdoor activity."},
+		{300, "Heavily Polluted", "Sensitive groups should stay indoors and avoid physical activity; others should reduce outdoor activity."},
+		{500, "Severely Polluted", "Everyone should avoid outdoor activity; sensitive groups should stay indoors."},
+	},
+	UKDAQI: {
+		{3, "Low", "Enjoy your usual outdoor activities."},
+		{6, "Moderate", "Adults and children with lung or heart problems who experience symptoms should consider reducing strenuous physical activity outdoors."},
+		{9, "High", "Anyone experiencing discomfort should consider reducing activity outdoors; at-risk groups should reduce strenuous physical exertion."},
+		{10, "Very High", "Reduce physical exertion outdoors, particularly if you experience symptoms such as cough or sore throat."},
+	},
+	IndiaNAQI: {
+		{50, "Good", "Minimal impact."},
+		{100, "Satisfactory", "Minor breathing discomfort to sensitive people."},
+		{200, "Moderate", "Breathing discomfort to people with lung disease such as asthma, and to people with heart disease, children and older adults."},
+		{300, "Poor", "Breathing discomfort to most people on prolonged exposure."},
+		{400, "Very Poor", "Respiratory illness on prolonged exposure."},
+		{500, "Severe", "Affects healthy people and seriously impacts those with existing diseases."},
+	},
+}
+
+// categorize returns the band name and health advice for an index value.
+// A max of -1 marks an open-ended top band.
+func categorize(standard Standard, value int) (string, string) {
+	bands := categories[standard]
+	for _, band := range bands {
+		if band.max < 0 || value <= band.max {
+			return band.name, band.advice
+		}
+	}
+	if len(bands) == 0 {
+		return "", ""
+	}
+	last := bands[len(bands)-1]
+	return last.name, last.advice
+}
@@ -0,0 +1,201 @@
+// Package proxyproto accepts HAProxy PROXY protocol v1 and v2 headers from
+// trusted load balancers so connections report the original client address.
+package proxyproto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Longest possible v1 header including CRLF, per the specification
+const v1MaxLength = 107
+
+var (
+	v1Prefix    = []byte("PROXY ")
+	v2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+	ErrInvalidHeader = errors.New("invalid PROXY protocol header")
+)
+
+// Listener wraps accepted connections. Only peers for which Trusted returns
+// true may send a header; bytes from other peers are passed through as is,
+// so clients cannot spoof their address.
+type Listener struct {
+	net.Listener
+	Trusted       func(ip net.IP) bool
+	HeaderTimeout time.Duration
+}
+
+func NewListener(inner net.Listener, trusted func(ip net.IP) bool) *Listener {
+	return &Listener{Listener: inner, Trusted: trusted, HeaderTimeout: 5 * time.Second}
+}
+
+func (l *Listener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &Conn{Conn: conn, trusted: l.Trusted, timeout: l.HeaderTimeout}, nil
+}
+
+// Conn reads the header lazily, on the first Read or RemoteAddr call, so a
+// slow peer cannot stall the accept loop
+type Conn struct {
+	net.Conn
+	trusted func(ip net.IP) bool
+	timeout time.Duration
+
+	once   sync.Once
+	reader *bufio.Reader
+	remote net.Addr
+	err    error
+}
+
+func (c *Conn) init() {
+	c.once.Do(func() {
+		c.reader = bufio.NewReader(c.Conn)
+		c.remote = c.Conn.RemoteAddr()
+
+		tcpAddr, ok := c.remote.(*net.TCPAddr)
+		if !ok || !c.trusted(tcpAddr.IP) {
+			return
+		}
+
+		c.Conn.SetReadDeadline(time.Now().Add(c.timeout))
+		defer c.Conn.SetReadDeadline(time.Time{})
+
+		addr, err := readHeader(c.reader)
+		if err != nil {
+			c.err = err
+			return
+		}
+		if addr != nil {
+			c.remote = addr
+		}
+	})
+}
+
+func (c *Conn) Read(b []byte) (int, error) {
+	c.init()
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.reader.Read(b)
+}
+
+// RemoteAddr returns the client address from the header, if one was sent
+func (c *Conn) RemoteAddr() net.Addr {
+	c.init()
+	return c.remote
+}
+
+// readHeader consumes a v1 or v2 header. It returns a nil address when the
+// stream does not start with a header or the header carries no address.
+func readHeader(reader *bufio.Reader) (net.Addr, error) {
+	first, err := reader.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+
+	switch first[0] {
+	case v1Prefix[0]:
+		if prefix, err := reader.Peek(len(v1Prefix)); err == nil && bytes.Equal(prefix, v1Prefix) {
+			return readV1(reader)
+		}
+	case v2Signature[0]:
+		if signature, err := reader.Peek(len(v2Signature)); err == nil && bytes.Equal(signature, v2Signature) {
+			return readV2(reader)
+		}
+	}
+	return nil, nil
+}
+
+// readV1 parses "PROXY TCP4 <src> <dst> <srcport> <dstport>\r\n"
+func readV1(reader *bufio.Reader) (net.Addr, error) {
+	var line []byte
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+		if len(line) >= v1MaxLength {
+			return nil, ErrInvalidHeader
+		}
+	}
+
+	fields := strings.Fields(strings.TrimSuffix(string(line), "\r\n"))
+	if len(fields) < 2 {
+		return nil, ErrInvalidHeader
+	}
+	if fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, ErrInvalidHeader
+	}
+
+	ip := net.ParseIP(fields[2])
+	port, err := strconv.Atoi(fields[4])
+	if ip == nil || err != nil || port < 0 || port > 65535 {
+		return nil, ErrInvalidHeader
+	}
+	return &net.TCPAddr{IP: ip, Port: port}, nil
+}
+
+// readV2 parses the binary header: signature, version/command, family,
+// address length and the addresses
+func readV2(reader *bufio.Reader) (net.Addr, error) {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, err
+	}
+
+	version, command := header[12]>>4, header[12]&0x0f
+	family := header[13]
+	length := binary.BigEndian.Uint16(header[14:16])
+	if version != 2 {
+		return nil, ErrInvalidHeader
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return nil, err
+	}
+
+	// LOCAL connections come from the proxy itself, e.g. health checks
+	if command == 0 {
+		return nil, nil
+	}
+	if command != 1 {
+		return nil, fmt.Errorf("%w: unknown command %d", ErrInvalidHeader, command)
+	}
+
+	switch family >> 4 {
+	case 1: // IPv4
+		if len(payload) < 12 {
+			return nil, ErrInvalidHeader
+		}
+		return &net.TCPAddr{IP: net.IP(payload[0:4]), Port: int(binary.BigEndian.Uint16(payload[8:10]))}, nil
+	case 2: // IPv6
+		if len(payload) < 36 {
+			return nil, ErrInvalidHeader
+		}
+		return &net.TCPAddr{IP: net.IP(payload[0:16]), Port: int(binary.BigEndian.Uint16(payload[32:34]))}, nil
+	default:
+		// Unix sockets and unspecified families carry no usable address
+		return nil, nil
+	}
+}
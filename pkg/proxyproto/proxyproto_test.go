@@ -0,0 +1,142 @@
+package proxyproto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+// v2Header builds a binary header with the given command, family and payload
+func v2Header(command, family byte, payload []byte) []byte {
+	header := append([]byte{}, v2Signature...)
+	header = append(header, 0x20|command, family)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(payload)))
+	return append(header, payload...)
+}
+
+func v2IPv4Payload() []byte {
+	payload := []byte{203, 0, 113, 7, 10, 0, 0, 1}
+	payload = binary.BigEndian.AppendUint16(payload, 4000)
+	return binary.BigEndian.AppendUint16(payload, 443)
+}
+
+func v2IPv6Payload() []byte {
+	payload := append([]byte{}, net.ParseIP("2001:db8::5").To16()...)
+	payload = append(payload, net.ParseIP("2001:db8::1").To16()...)
+	payload = binary.BigEndian.AppendUint16(payload, 4000)
+	return binary.BigEndian.AppendUint16(payload, 443)
+}
+
+func TestReadHeader(t *testing.T) {
+	cases := []struct {
+		name  string
+		input []byte
+		want  string
+		err   error
+		rest  string
+	}{
+		{"v1 tcp4", []byte("PROXY TCP4 203.0.113.7 10.0.0.1 4000 443\r\nGET /"), "203.0.113.7:4000", nil, "GET /"},
+		{"v1 tcp6", []byte("PROXY TCP6 2001:db8::5 2001:db8::1 4000 443\r\n"), "[2001:db8::5]:4000", nil, ""},
+		{"v1 unknown", []byte("PROXY UNKNOWN\r\nGET /"), "", nil, "GET /"},
+		{"v1 bad address", []byte("PROXY TCP4 nowhere 10.0.0.1 4000 443\r\n"), "", ErrInvalidHeader, ""},
+		{"v1 bad port", []byte("PROXY TCP4 203.0.113.7 10.0.0.1 70000 443\r\n"), "", ErrInvalidHeader, ""},
+		{"v1 missing fields", []byte("PROXY TCP4 203.0.113.7\r\n"), "", ErrInvalidHeader, ""},
+		{"v1 oversized", []byte("PROXY TCP4 " + strings.Repeat("1", 200) + "\r\n"), "", ErrInvalidHeader, ""},
+		{"v1 truncated", []byte("PROXY TCP4 203.0.113.7 10.0.0.1"), "", io.EOF, ""},
+		{"v2 ipv4", append(v2Header(1, 0x11, v2IPv4Payload()), "GET /"...), "203.0.113.7:4000", nil, "GET /"},
+		{"v2 ipv6", v2Header(1, 0x21, v2IPv6Payload()), "[2001:db8::5]:4000", nil, ""},
+		{"v2 local", append(v2Header(0, 0x00, nil), "GET /"...), "", nil, "GET /"},
+		{"v2 unix", v2Header(1, 0x31, make([]byte, 216)), "", nil, ""},
+		{"v2 bad version", append(append([]byte{}, v2Signature...), 0x11, 0x11, 0, 0), "", ErrInvalidHeader, ""},
+		{"v2 unknown command", v2Header(2, 0x11, v2IPv4Payload()), "", ErrInvalidHeader, ""},
+		{"v2 short ipv4 payload", v2Header(1, 0x11, v2IPv4Payload()[:8]), "", ErrInvalidHeader, ""},
+		{"v2 truncated header", v2Signature[:12], "", io.ErrUnexpectedEOF, ""},
+		{"v2 truncated payload", v2Header(1, 0x11, v2IPv4Payload())[:20], "", io.ErrUnexpectedEOF, ""},
+		{"v2 length beyond input", v2Header(1, 0x11, v2IPv4Payload()[:4])[:14], "", io.ErrUnexpectedEOF, ""},
+		{"no header", []byte("GET / HTTP/1.1\r\n"), "", nil, "GET / HTTP/1.1\r\n"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			reader := bufio.NewReader(bytes.NewReader(c.input))
+			addr, err := readHeader(reader)
+			if c.err != nil {
+				if !errors.Is(err, c.err) {
+					t.Fatalf("err = %v, want %v", err, c.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v, want nil", err)
+			}
+			got := ""
+			if addr != nil {
+				got = addr.String()
+			}
+			if got != c.want {
+				t.Errorf("addr = %q, want %q", got, c.want)
+			}
+			if rest, _ := io.ReadAll(reader); string(rest) != c.rest {
+				t.Errorf("rest = %q, want %q", rest, c.rest)
+			}
+		})
+	}
+}
+
+func TestListenerTrustsOnlyProxies(t *testing.T) {
+	cases := []struct {
+		name    string
+		trusted bool
+		want    string
+	}{
+		{"trusted peer", true, "203.0.113.7:4000"},
+		{"untrusted peer", false, "127.0.0.1"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			inner, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			listener := NewListener(inner, func(net.IP) bool { return c.trusted })
+			defer listener.Close()
+
+			header := "PROXY TCP4 203.0.113.7 10.0.0.1 4000 443\r\n"
+			go func() {
+				client, err := net.Dial("tcp", inner.Addr().String())
+				if err != nil {
+					return
+				}
+				defer client.Close()
+				io.WriteString(client, header+"hello")
+			}()
+
+			conn, err := listener.Accept()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			got := conn.RemoteAddr().String()
+			if !c.trusted {
+				got, _, _ = net.SplitHostPort(got)
+			}
+			if got != c.want {
+				t.Errorf("RemoteAddr = %q, want %q", got, c.want)
+			}
+
+			body, _ := io.ReadAll(conn)
+			want := "hello"
+			if !c.trusted {
+				want = header + "hello"
+			}
+			if string(body) != want {
+				t.Errorf("body = %q, want %q", body, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package utils
+
+import "sync"
+
+// RunBatch runs the jobs on a bounded number of workers and waits for them
+func RunBatch(jobs []func(), workers int) {
+	queue := make(chan func())
+	var wg sync.WaitGroup
+	for w := 0; w < max(1, min(workers, len(jobs))); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				job()
+			}
+		}()
+	}
+	for _, job := range jobs {
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+}
@@ -0,0 +1,54 @@
+package utils
+
+// Pollen risk levels shared by every source
+const (
+	PollenNone = iota
+	PollenLow
+	PollenModerate
+	PollenHigh
+	PollenVeryHigh
+)
+
+var pollenLevelNames = []string{"none", "low", "moderate", "high", "very_high"}
+
+// Lower bounds (grains/m³) of the low, moderate, high and very high levels,
+// following the National Allergy Bureau thresholds for trees, grasses and weeds
+var (
+	treePollenThresholds  = [4]float64{1, 15, 90, 1500}
+	grassPollenThresholds = [4]float64{1, 5, 20, 200}
+	weedPollenThresholds  = [4]float64{1, 10, 50, 500}
+)
+
+var pollenThresholds = map[string][4]float64{
+	"alder":   treePollenThresholds,
+	"birch":   treePollenThresholds,
+	"olive":   treePollenThresholds,
+	"grass":   grassPollenThresholds,
+	"mugwort": weedPollenThresholds,
+	"ragweed": weedPollenThresholds,
+}
+
+// ToPollenLevel classifies a pollen concentration for the given species.
+// Unknown species use the tree thresholds.
+func ToPollenLevel(species string, concentration float64) int {
+	thresholds, ok := pollenThresholds[species]
+	if !ok {
+		thresholds = treePollenThresholds
+	}
+
+	level := PollenNone
+	for i, threshold := range thresholds {
+		if concentration >= threshold {
+			level = i + 1
+		}
+	}
+	return level
+}
+
+// PollenLevelName returns the API name of a pollen level
+func PollenLevelName(level int) string {
+	if level < 0 || level >= len(pollenLevelNames) {
+		return pollenLevelNames[PollenNone]
+	}
+	return pollenLevelNames[level]
+}